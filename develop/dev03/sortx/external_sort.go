@@ -0,0 +1,277 @@
+package sortx
+
+import (
+	"bufio"
+	"container/heap"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+)
+
+// defaultBufferSize - размер буфера для сортировки в памяти, если он не указан в Options
+const defaultBufferSize = 256 << 20
+
+// lineOverhead - примерный расход памяти на хранение одной строки помимо ее содержимого
+const lineOverhead = 16
+
+// maxMergeRuns - максимальное количество временных файлов, сливаемых за один проход
+const maxMergeRuns = 64
+
+// externalSorter - класс для сортировки данных, не помещающихся в память
+type externalSorter struct {
+	context    *sortContext // контекст сортировщика для сортировки отдельных частей
+	bufferSize int64        // максимальный размер части, сортируемой в памяти
+	tempDir    string       // директория для временных файлов
+	unique     bool
+	dups       bool
+	split      bufio.SplitFunc // функция разделения временных файлов на строки или записи
+	runs       []string        // пути к временным файлам с отсортированными частями
+}
+
+// readRuns - метод для чтения строк из входных файлов частями размером не больше bufferSize
+// если все строки поместились в память, они возвращаются без сортировки, иначе каждая часть
+// сортируется и записывается во временный файл
+func (e *externalSorter) readRuns(inputs []io.Reader) ([]string, error) {
+	lines := make([]string, 0)
+	var size int64
+	// входные файлы читаются по очереди, как если бы они были объединены
+	for _, input := range inputs {
+		scanner := newScanner(input, e.split)
+		for scanner.Scan() {
+			line := scanner.Text()
+			// выгрузка части во временный файл при превышении размера буфера
+			if size+int64(len(line))+lineOverhead > e.bufferSize && len(lines) > 0 {
+				if err := e.writeRun(lines); err != nil {
+					return nil, err
+				}
+				lines = make([]string, 0)
+				size = 0
+			}
+			lines = append(lines, line)
+			size += int64(len(line)) + lineOverhead
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+	// если временных файлов нет, все строки помещаются в память
+	if len(e.runs) == 0 {
+		return lines, nil
+	}
+	if len(lines) > 0 {
+		if err := e.writeRun(lines); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// writeRun - метод для сортировки части строк и записи ее во временный файл
+func (e *externalSorter) writeRun(lines []string) error {
+	e.context.sortLines(&lines)
+
+	file, err := os.CreateTemp(e.tempDir, "sort-run-*")
+	if err != nil {
+		return err
+	}
+	e.runs = append(e.runs, file.Name())
+
+	if err = writeLines(file, lines); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// mergeRuns - метод для слияния временных файлов в итоговый результат
+func (e *externalSorter) mergeRuns(w io.Writer) error {
+	// если файлов слишком много, они сливаются в несколько проходов
+	// результат слияния занимает место первых файлов, чтобы сохранить порядок равных строк
+	for len(e.runs) > maxMergeRuns {
+		file, err := os.CreateTemp(e.tempDir, "sort-run-*")
+		if err != nil {
+			return err
+		}
+		runs := slices.Clone(e.runs[:maxMergeRuns])
+		e.runs = append([]string{file.Name()}, e.runs[maxMergeRuns:]...)
+
+		// промежуточное слияние сохраняет все строки группы, чтобы их можно было посчитать при dups
+		if err = e.mergeFiles(runs, e.newGroupWriter(file, false)); err != nil {
+			_ = file.Close()
+			return err
+		}
+		if err = file.Close(); err != nil {
+			return err
+		}
+		removeFiles(runs)
+	}
+	return e.mergeFiles(e.runs, e.newGroupWriter(w, e.dups))
+}
+
+// mergeFiles - метод для k-путевого слияния отсортированных файлов
+func (e *externalSorter) mergeFiles(paths []string, output *groupWriter) error {
+	// открытие всех файлов для чтения
+	files := make([]*os.File, 0, len(paths))
+	readers := make([]io.Reader, 0, len(paths))
+	defer func() {
+		for _, file := range files {
+			_ = file.Close()
+		}
+	}()
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		files = append(files, file)
+		readers = append(readers, file)
+	}
+	return e.mergeReaders(readers, output)
+}
+
+// mergeReaders - метод для k-путевого слияния отсортированных потоков строк
+func (e *externalSorter) mergeReaders(readers []io.Reader, output *groupWriter) error {
+	queue := &mergeQueue{sorter: e.context.sort}
+	for run, reader := range readers {
+		scanner := newScanner(reader, e.split)
+		if scanner.Scan() {
+			queue.items = append(queue.items, &mergeItem{line: scanner.Text(), scanner: scanner, run: run})
+		} else if err := scanner.Err(); err != nil {
+			return err
+		}
+	}
+	heap.Init(queue)
+
+	for queue.Len() > 0 {
+		item := queue.items[0]
+		if err := output.writeLine(item.line); err != nil {
+			return err
+		}
+		// чтение следующей строки из того же файла
+		if item.scanner.Scan() {
+			item.line = item.scanner.Text()
+			heap.Fix(queue, 0)
+		} else {
+			if err := item.scanner.Err(); err != nil {
+				return err
+			}
+			heap.Pop(queue)
+		}
+	}
+	return output.flush()
+}
+
+// cleanup - метод для удаления временных файлов
+func (e *externalSorter) cleanup() {
+	removeFiles(e.runs)
+	e.runs = nil
+}
+
+// removeFiles - функция для удаления списка файлов
+func removeFiles(paths []string) {
+	for _, path := range paths {
+		_ = os.Remove(path)
+	}
+}
+
+// mergeItem - текущая строка одного из сливаемых файлов
+type mergeItem struct {
+	line    string
+	scanner *bufio.Scanner
+	run     int // номер файла, используется для сохранения порядка равных строк
+}
+
+// mergeQueue - очередь с приоритетом для слияния, реализует heap.Interface
+type mergeQueue struct {
+	items  []*mergeItem
+	sorter iSorter
+}
+
+func (q *mergeQueue) Len() int { return len(q.items) }
+
+func (q *mergeQueue) Less(i, j int) bool {
+	result := q.sorter.compareLines(q.items[i].line, q.items[j].line)
+	if result == 0 {
+		return q.items[i].run < q.items[j].run
+	}
+	return result < 0
+}
+
+func (q *mergeQueue) Swap(i, j int) { q.items[i], q.items[j] = q.items[j], q.items[i] }
+
+func (q *mergeQueue) Push(x any) { q.items = append(q.items, x.(*mergeItem)) }
+
+func (q *mergeQueue) Pop() any {
+	item := q.items[len(q.items)-1]
+	q.items = q.items[:len(q.items)-1]
+	return item
+}
+
+// newGroupWriter - метод для создания записи результата с группировкой строк с равными ключами
+func (e *externalSorter) newGroupWriter(w io.Writer, dups bool) *groupWriter {
+	return &groupWriter{writer: bufio.NewWriter(w), sorter: e.context.sort, unique: e.unique, dups: dups}
+}
+
+// groupWriter - класс для записи отсортированных строк, объединяющий подряд идущие строки с равными ключами
+type groupWriter struct {
+	writer *bufio.Writer
+	sorter iSorter
+	unique bool   // вывод только первой строки группы
+	dups   bool   // вывод только групп из нескольких строк с их количеством, как у uniq -c -d
+	first  string // первая строка текущей группы
+	count  int    // количество строк в текущей группе
+}
+
+// writeLine - метод для записи очередной строки
+func (g *groupWriter) writeLine(line string) error {
+	if !g.unique && !g.dups {
+		_, err := g.writer.WriteString(line + "\n")
+		return err
+	}
+	// строка с теми же ключами, что и у первой строки группы, добавляется в группу
+	if g.count > 0 && g.sorter.compareLines(g.first, line) == 0 {
+		g.count++
+		return nil
+	}
+	if err := g.writeGroup(); err != nil {
+		return err
+	}
+	g.first, g.count = line, 1
+	return nil
+}
+
+// writeGroup - метод для записи текущей группы строк
+func (g *groupWriter) writeGroup() error {
+	var err error
+	switch {
+	case g.count == 0:
+	case g.dups:
+		if g.count > 1 {
+			_, err = fmt.Fprintf(g.writer, "%7d %s\n", g.count, g.first)
+		}
+	case g.unique:
+		_, err = g.writer.WriteString(g.first + "\n")
+	}
+	return err
+}
+
+// flush - метод для записи последней группы и сброса буфера
+func (g *groupWriter) flush() error {
+	if err := g.writeGroup(); err != nil {
+		return err
+	}
+	g.count = 0
+	return g.writer.Flush()
+}
+
+// writeLines - функция для записи строк через буфер
+func writeLines(w io.Writer, lines []string) error {
+	writer := bufio.NewWriter(w)
+	for _, line := range lines {
+		if _, err := writer.WriteString(line + "\n"); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
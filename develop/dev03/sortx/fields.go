@@ -0,0 +1,135 @@
+package sortx
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"math"
+	"strings"
+)
+
+// iSplitter - интерфейс для разделения строки на поля
+type iSplitter interface {
+	splitFields(string) []string
+	separator() string
+}
+
+// blankSplitter - разделение строки на поля по пробелам
+// как и в GNU sort, поле включает в себя предшествующие ему пробелы
+type blankSplitter struct{}
+
+// splitFields - реализация метода splitFields интерфейса iSplitter классом blankSplitter
+func (b *blankSplitter) splitFields(line string) []string {
+	fields := make([]string, 0)
+	start := 0
+	for start < len(line) {
+		end := start
+		// пропуск пробелов перед полем
+		for end < len(line) && strings.IndexByte(blanks, line[end]) != -1 {
+			end++
+		}
+		// пропуск содержимого поля
+		for end < len(line) && strings.IndexByte(blanks, line[end]) == -1 {
+			end++
+		}
+		fields = append(fields, line[start:end])
+		start = end
+	}
+	return fields
+}
+
+// separator - реализация метода separator интерфейса iSplitter классом blankSplitter
+func (b *blankSplitter) separator() string {
+	return "" // пробелы уже входят в поля
+}
+
+// delimiterSplitter - разделение строки на поля по символу из ключа -t
+type delimiterSplitter struct {
+	delimiter string
+}
+
+// splitFields - реализация метода splitFields интерфейса iSplitter классом delimiterSplitter
+func (d *delimiterSplitter) splitFields(line string) []string {
+	return strings.Split(line, d.delimiter)
+}
+
+// separator - реализация метода separator интерфейса iSplitter классом delimiterSplitter
+func (d *delimiterSplitter) separator() string {
+	return d.delimiter
+}
+
+// csvSplitter - разделение записи на поля по RFC 4180 с учетом кавычек
+type csvSplitter struct {
+	comma rune
+}
+
+// splitFields - реализация метода splitFields интерфейса iSplitter классом csvSplitter
+func (c *csvSplitter) splitFields(record string) []string {
+	// завершающий CR записи CRLF сохраняется в выводе, но не входит в последнее поле
+	reader := csv.NewReader(strings.NewReader(strings.TrimSuffix(record, "\r")))
+	reader.Comma = c.comma
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	fields, err := reader.Read()
+	// пустая запись не содержит полей
+	if err == io.EOF {
+		return []string{}
+	}
+	// запись, которую не удалось разобрать, считается одним полем
+	if err != nil {
+		return []string{record}
+	}
+	return fields
+}
+
+// separator - реализация метода separator интерфейса iSplitter классом csvSplitter
+func (c *csvSplitter) separator() string {
+	return string(c.comma)
+}
+
+// initialScanBufferSize - начальный размер буфера для чтения строк, при длинных строках буфер растет
+const initialScanBufferSize = 64 << 10
+
+// newScanner - функция для создания сканера входных данных с функцией разделения split
+// в отличие от bufio.Scanner по умолчанию длина строки ограничена только доступной памятью
+func newScanner(r io.Reader, split bufio.SplitFunc) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, initialScanBufferSize), math.MaxInt)
+	scanner.Split(split)
+	return scanner
+}
+
+// scanLines - функция разделения для bufio.Scanner, возвращающая строки без завершающего перевода строки
+// в отличие от bufio.ScanLines символ CR в конце строки сохраняется, поэтому строки CRLF выводятся без изменений
+func scanLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	// последняя строка без перевода строки
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// scanCSVRecords - функция разделения для bufio.Scanner, возвращающая записи CSV целиком
+// перевод строки внутри кавычек не завершает запись, исходный текст записи, включая CR, не изменяется
+func scanCSVRecords(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	inQuotes := false
+	for i, char := range data {
+		switch char {
+		case '"':
+			inQuotes = !inQuotes // экранированные кавычки "" переключают состояние дважды
+		case '\n':
+			if !inQuotes {
+				return i + 1, data[:i], nil
+			}
+		}
+	}
+	// последняя запись без перевода строки
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
@@ -0,0 +1,163 @@
+package sortx
+
+import (
+	"io"
+	"os"
+)
+
+// SortFiles - функция для сортировки строк из файлов paths с записью результата в файл output
+// путь "-" означает чтение из stdin, пустой output - запись в stdout
+// файл результата создается только после чтения всех входных файлов, поэтому он может совпадать с одним из них
+func SortFiles(paths []string, output string, opts Options) error {
+	inputs, closeInputs, err := openInputs(paths)
+	if err != nil {
+		return err
+	}
+	defer closeInputs()
+	return writeOutput(output, func(w io.Writer) error {
+		return SortReaders(inputs, w, opts)
+	})
+}
+
+// MergeFiles - функция для слияния уже отсортированных файлов paths с записью результата в файл output
+// входной файл, совпадающий с файлом результата, копируется во временный файл до его перезаписи
+func MergeFiles(paths []string, output string, opts Options) error {
+	inputs, closeInputs, err := openInputs(paths)
+	if err != nil {
+		return err
+	}
+	defer closeInputs()
+
+	if output != "" {
+		for i, input := range inputs {
+			file, ok := input.(*os.File)
+			if !ok || !isSameFile(file, output) {
+				continue
+			}
+			inputCopy, err := copyToTemp(file, opts.TempDir)
+			if inputCopy != nil {
+				defer func(inputCopy *os.File) {
+					_ = inputCopy.Close()
+				}(inputCopy)
+			}
+			if err != nil {
+				return err
+			}
+			inputs[i] = inputCopy
+		}
+	}
+	return writeOutput(output, func(w io.Writer) error {
+		return Merge(inputs, w, opts)
+	})
+}
+
+// openInputs - функция для открытия входных файлов, путь "-" означает stdin
+// возвращает функцию для закрытия открытых файлов
+func openInputs(paths []string) ([]io.Reader, func(), error) {
+	inputs := make([]io.Reader, 0, len(paths))
+	files := make([]*os.File, 0, len(paths))
+	closeFiles := func() {
+		for _, file := range files {
+			_ = file.Close()
+		}
+	}
+	for _, path := range paths {
+		if path == "-" {
+			inputs = append(inputs, os.Stdin)
+			continue
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			closeFiles()
+			return nil, nil, err
+		}
+		files = append(files, file)
+		inputs = append(inputs, file)
+	}
+	return inputs, closeFiles, nil
+}
+
+// writeOutput - функция для записи результата функцией write в файл output, пустой output - запись в stdout
+func writeOutput(output string, write func(io.Writer) error) error {
+	if output == "" {
+		return write(os.Stdout)
+	}
+	file := &outputFile{name: output}
+	if err := write(file); err != nil {
+		file.abort()
+		return err
+	}
+	return file.Close()
+}
+
+// outputFile - файл результата, который создается только при первой записи или закрытии,
+// поэтому он может совпадать с одним из входных файлов
+type outputFile struct {
+	name string
+	file *os.File
+}
+
+// Write - реализация метода Write интерфейса io.Writer классом outputFile
+func (o *outputFile) Write(p []byte) (int, error) {
+	if err := o.open(); err != nil {
+		return 0, err
+	}
+	return o.file.Write(p)
+}
+
+// Close - метод для закрытия файла, пустой результат тоже создает файл
+func (o *outputFile) Close() error {
+	if err := o.open(); err != nil {
+		return err
+	}
+	return o.file.Close()
+}
+
+// abort - метод для закрытия файла при ошибке, не созданный файл при этом не создается
+func (o *outputFile) abort() {
+	if o.file != nil {
+		_ = o.file.Close()
+	}
+}
+
+// open - метод для создания файла, если он еще не создан
+func (o *outputFile) open() error {
+	if o.file != nil {
+		return nil
+	}
+	file, err := os.Create(o.name)
+	if err != nil {
+		return err
+	}
+	o.file = file
+	return nil
+}
+
+// isSameFile - функция для проверки, указывает ли путь на уже открытый файл
+func isSameFile(file *os.File, path string) bool {
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	pathInfo, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(fileInfo, pathInfo)
+}
+
+// copyToTemp - функция для копирования входного файла во временный файл
+// используется, когда файл результата совпадает с одним из сливаемых файлов
+func copyToTemp(input io.Reader, tempDir string) (*os.File, error) {
+	file, err := os.CreateTemp(tempDir, "sort-input-*")
+	if err != nil {
+		return nil, err
+	}
+	// файл удаляется сразу, данные остаются доступны до его закрытия
+	_ = os.Remove(file.Name())
+	if _, err = io.Copy(file, input); err != nil {
+		return file, err
+	}
+	_, err = file.Seek(0, io.SeekStart)
+	return file, err
+}
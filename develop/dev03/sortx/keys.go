@@ -0,0 +1,193 @@
+package sortx
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// blanks - символы, разделяющие поля строки
+const blanks = " \t"
+
+// sortKey - ключ сортировки с собственной стратегией сравнения и модификаторами
+type sortKey struct {
+	lowerColumn  int       // индекс первого поля ключа
+	lowerChar    int       // количество символов, пропускаемых в первом поле ключа
+	higherColumn int       // индекс поля, следующего за последним полем ключа, 0 - до конца строки
+	higherChar   int       // количество символов последнего поля, входящих в ключ, 0 - до конца поля
+	comparer     iComparer // стратегия сравнения значений ключа
+	reverse      bool      // модификатор r
+	ignoreBlanks bool      // модификатор b
+	foldCase     bool      // модификатор f
+}
+
+// charOffset - функция для получения смещения в байтах после первых chars символов поля
+// при ignoreBlanks лидирующие пробелы поля пропускаются и не учитываются в chars
+func charOffset(field string, chars int, ignoreBlanks bool) int {
+	offset := 0
+	if ignoreBlanks {
+		offset = len(field) - len(strings.TrimLeft(field, blanks))
+	}
+	for ; chars > 0 && offset < len(field); chars-- {
+		_, size := utf8.DecodeRuneInString(field[offset:])
+		offset += size
+	}
+	return offset
+}
+
+// extract - метод для получения значения ключа из полей строки, объединенных через separator
+func (k *sortKey) extract(fields []string, separator string) string {
+	// ключ за пределами строки считается пустым
+	if k.lowerColumn >= len(fields) {
+		return ""
+	}
+	// получение индекса последнего поля ключа и количества его символов
+	// ключ, выходящий за пределы строки, продолжается до конца строки
+	lastColumn, lastChars := len(fields)-1, 0
+	if k.higherColumn > 0 && k.higherColumn <= len(fields) {
+		lastColumn, lastChars = k.higherColumn-1, k.higherChar
+	}
+
+	// смещения начала ключа в первом поле и конца ключа в последнем поле
+	start := charOffset(fields[k.lowerColumn], k.lowerChar, k.ignoreBlanks)
+	end := len(fields[lastColumn])
+	if lastChars > 0 {
+		end = charOffset(fields[lastColumn], lastChars, k.ignoreBlanks)
+	}
+
+	var key string
+	if lastColumn == k.lowerColumn {
+		// конец ключа до его начала в том же поле дает пустой ключ
+		if end <= start {
+			return ""
+		}
+		key = fields[k.lowerColumn][start:end]
+	} else {
+		keyFields := make([]string, 0, lastColumn-k.lowerColumn+1)
+		keyFields = append(keyFields, fields[k.lowerColumn][start:])
+		keyFields = append(keyFields, fields[k.lowerColumn+1:lastColumn]...)
+		keyFields = append(keyFields, fields[lastColumn][:end])
+		key = strings.Join(keyFields, separator)
+	}
+	// приведение к верхнему регистру на основании поля foldCase
+	if k.foldCase {
+		key = strings.ToUpper(key)
+	}
+	return key
+}
+
+// KeySpec - описание ключа сортировки, аналог ключа -k в GNU sort
+type KeySpec struct {
+	StartField int    // номер первого поля ключа, начиная с 1
+	StartChar  int    // номер первого символа ключа в первом поле, начиная с 1, 0 - с начала поля
+	EndField   int    // номер последнего поля ключа, 0 - до конца строки
+	EndChar    int    // номер последнего символа ключа в последнем поле, 0 - до конца поля
+	Modifiers  string // модификаторы ключа: n, h, M, g, V, R, r, b, f
+}
+
+// modifierLetters - допустимые модификаторы ключей
+const modifierLetters = "nhMgVRrbf"
+
+// ParseKey - функция для разбора ключа в формате POS1[,POS2], где POS - F[.C][OPTS]:
+// номер поля F, номер символа в поле C и модификаторы, например 2,2n или 3.2b,3.5
+func ParseKey(spec string) (KeySpec, error) {
+	key := KeySpec{}
+
+	positions := strings.Split(spec, ",")
+	if len(positions) > 2 {
+		return key, errors.New("too many positions in key " + spec)
+	}
+	for i, position := range positions {
+		// разделение номера поля, номера символа и модификаторов
+		field, position := splitNumber(position)
+		column, err := strconv.Atoi(field)
+		if err != nil {
+			return key, errors.New("non-numerical column index in key " + spec)
+		}
+		char := 0
+		if strings.HasPrefix(position, ".") {
+			var digits string
+			digits, position = splitNumber(position[1:])
+			char, err = strconv.Atoi(digits)
+			if err != nil {
+				return key, errors.New("non-numerical character index in key " + spec)
+			}
+			// как и в GNU sort, номер символа начала ключа не может быть нулевым
+			if i == 0 && char == 0 {
+				return key, errors.New("character index must start from 1 in key " + spec)
+			}
+		}
+		if i == 0 {
+			key.StartField, key.StartChar = column, char
+		} else {
+			key.EndField, key.EndChar = column, char
+		}
+		// проверка модификаторов
+		for _, modifier := range position {
+			if !strings.ContainsRune(modifierLetters, modifier) {
+				return key, errors.New("unknown modifier " + string(modifier) + " in key " + spec)
+			}
+		}
+		key.Modifiers += position
+	}
+	return key, nil
+}
+
+// splitNumber - функция для разделения строки на число в начале и остаток
+func splitNumber(position string) (string, string) {
+	digits := len(position) - len(strings.TrimLeft(position, "0123456789"))
+	return position[:digits], position[digits:]
+}
+
+// newSortKey - функция для создания ключа сортировки по его описанию
+// ключ без модификаторов получает стратегию и модификаторы из defaultKey
+func newSortKey(spec KeySpec, defaultKey sortKey, textComparer iComparer, comparers map[rune]iComparer) (sortKey, error) {
+	if spec.StartField < 1 || spec.EndField < 0 {
+		return sortKey{}, errors.New("column indexes start from 1")
+	}
+	if spec.StartChar < 0 || spec.EndChar < 0 {
+		return sortKey{}, errors.New("character indexes start from 1")
+	}
+	// сравнение номеров начального и конечного столбца
+	if spec.EndField != 0 && spec.EndField < spec.StartField {
+		return sortKey{}, errors.New("incorrect column indexes given")
+	}
+
+	// ключ без модификаторов наследует глобальные параметры сортировки
+	key := defaultKey
+	if spec.Modifiers != "" {
+		key = sortKey{comparer: textComparer}
+		if err := key.applyModifiers(spec.Modifiers, comparers); err != nil {
+			return sortKey{}, err
+		}
+	}
+	key.lowerColumn = spec.StartField - 1 // уменьшение номера первого столбца на 1 для получения корректного индекса
+	key.higherColumn = spec.EndField
+	// номер первого символа превращается в количество пропускаемых символов
+	key.lowerChar = max(spec.StartChar-1, 0)
+	key.higherChar = spec.EndChar
+	return key, nil
+}
+
+// applyModifiers - метод для применения модификаторов к ключу
+// модификаторы из comparers выбирают стратегию сравнения, остальные включают флаги ключа
+func (k *sortKey) applyModifiers(modifiers string, comparers map[rune]iComparer) error {
+	for _, modifier := range modifiers {
+		if comparer, ok := comparers[modifier]; ok {
+			k.comparer = comparer
+			continue
+		}
+		switch modifier {
+		case 'r':
+			k.reverse = true
+		case 'b':
+			k.ignoreBlanks = true
+		case 'f':
+			k.foldCase = true
+		default:
+			return errors.New("unknown modifier " + string(modifier))
+		}
+	}
+	return nil
+}
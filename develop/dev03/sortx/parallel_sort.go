@@ -0,0 +1,76 @@
+package sortx
+
+import (
+	"slices"
+	"sync"
+)
+
+// minParallelPartition - минимальное количество строк в одной части при параллельной сортировке
+const minParallelPartition = 4096
+
+// sortParallel - функция для сортировки слайса по частям в отдельных горутинах с последующим слиянием
+// при stable равные элементы сохраняют исходный порядок, результат совпадает с последовательной сортировкой
+func sortParallel[T any](items []T, workers int, compare func(T, T) int, stable bool) []T {
+	sortPart := slices.SortFunc[[]T]
+	if stable {
+		sortPart = slices.SortStableFunc[[]T]
+	}
+
+	// количество частей ограничено так, чтобы каждая часть была не меньше minParallelPartition
+	partsCount := min(workers, len(items)/minParallelPartition)
+	if partsCount < 2 {
+		sortPart(items, compare)
+		return items
+	}
+
+	// разделение слайса на части и их сортировка в отдельных горутинах
+	parts := make([][]T, partsCount)
+	partSize := (len(items) + partsCount - 1) / partsCount
+	var wg sync.WaitGroup
+	for i := range parts {
+		parts[i] = items[i*partSize : min((i+1)*partSize, len(items))]
+		wg.Add(1)
+		go func(part []T) {
+			defer wg.Done()
+			sortPart(part, compare)
+		}(parts[i])
+	}
+	wg.Wait()
+
+	// попарное слияние соседних частей, пока не останется одна
+	for len(parts) > 1 {
+		merged := make([][]T, (len(parts)+1)/2)
+		for i := range merged {
+			if 2*i+1 == len(parts) {
+				merged[i] = parts[2*i]
+				continue
+			}
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				merged[i] = mergeSorted(parts[2*i], parts[2*i+1], compare)
+			}(i)
+		}
+		wg.Wait()
+		parts = merged
+	}
+	return parts[0]
+}
+
+// mergeSorted - функция для слияния двух отсортированных слайсов
+// при равенстве элементов первым берется элемент из left, что сохраняет исходный порядок
+func mergeSorted[T any](left, right []T, compare func(T, T) int) []T {
+	result := make([]T, 0, len(left)+len(right))
+	i, j := 0, 0
+	for i < len(left) && j < len(right) {
+		if compare(right[j], left[i]) < 0 {
+			result = append(result, right[j])
+			j++
+		} else {
+			result = append(result, left[i])
+			i++
+		}
+	}
+	result = append(result, left[i:]...)
+	return append(result, right[j:]...)
+}
@@ -0,0 +1,156 @@
+package sortx
+
+import (
+	"bufio"
+	"io"
+	"slices"
+	"strings"
+)
+
+// iSorter - интерфейс для сортировщика
+type iSorter interface {
+	sortSlice(*[]string)
+	checkSlice(*[]string) int
+	compareLines(string, string) int
+}
+
+// iComparer - интерфейс для стратегии сравнения значений одного ключа
+type iComparer interface {
+	compareKeys(string, string) int
+}
+
+// sorter - основной класс сортировщика, сравнивающий строки цепочкой ключей
+type sorter struct {
+	keys     []sortKey // ключи сортировки в порядке приоритета
+	splitter iSplitter // стратегия разделения строк на поля
+	reverse  bool      // разворот сравнения строк целиком, если все ключи равны
+	unique   bool      // удаление строк с ключами, равными ключам предыдущей строки
+	stable   bool      // сохранение исходного порядка строк с равными ключами
+	workers  int       // количество горутин для параллельной сортировки
+}
+
+// sortItem - строка с заранее извлеченными значениями ключей
+type sortItem struct {
+	line string
+	keys []string
+}
+
+// newSortItem - метод для разделения строки на поля и извлечения значений всех ключей
+func (s *sorter) newSortItem(line string) sortItem {
+	fields := s.splitter.splitFields(line)
+	separator := s.splitter.separator()
+	keys := make([]string, len(s.keys))
+	for i, key := range s.keys {
+		keys[i] = key.extract(fields, separator)
+	}
+	return sortItem{line: line, keys: keys}
+}
+
+// sortSlice - реализация метода sortSlice интерфейса iSorter классом sorter
+func (s *sorter) sortSlice(lines *[]string) {
+	// извлечение ключей один раз для каждой строки, а не при каждом сравнении
+	items := make([]sortItem, len(*lines))
+	for i, line := range *lines {
+		items[i] = s.newSortItem(line)
+	}
+
+	// сортировка строк цепочкой ключей, строки переставляются без изменений
+	items = sortParallel(items, s.workers, s.compareItems, s.stable)
+
+	// удаление строк с повторяющимися ключами на основании поля unique, остается первая строка группы
+	if s.unique {
+		items = slices.CompactFunc(items, func(item1, item2 sortItem) bool {
+			return s.compareItems(item1, item2) == 0
+		})
+	}
+	*lines = (*lines)[:len(items)]
+	for i, item := range items {
+		(*lines)[i] = item.line
+	}
+}
+
+// checkSlice - реализация метода checkSlice интерфейса iSorter классом sorter
+// возвращает индекс первой строки, нарушающей порядок, или -1, если строки отсортированы
+func (s *sorter) checkSlice(lines *[]string) int {
+	for i := 1; i < len(*lines); i++ {
+		result := s.compareLines((*lines)[i-1], (*lines)[i])
+		// при unique равные строки тоже считаются нарушением порядка
+		if result > 0 || (s.unique && result == 0) {
+			return i
+		}
+	}
+	return -1
+}
+
+// compareLines - реализация метода compareLines интерфейса iSorter классом sorter
+func (s *sorter) compareLines(line1, line2 string) int {
+	return s.compareItems(s.newSortItem(line1), s.newSortItem(line2))
+}
+
+// compareItems - метод для сравнения строк по извлеченным значениям ключей
+func (s *sorter) compareItems(item1, item2 sortItem) int {
+	// сравнение по ключам, пока не найдется различие
+	for i, key := range s.keys {
+		result := key.comparer.compareKeys(item1.keys[i], item2.keys[i])
+		if key.reverse {
+			result = -result
+		}
+		if result != 0 {
+			return result
+		}
+	}
+	// в стабильном режиме строки с равными ключами считаются равными
+	if s.stable {
+		return 0
+	}
+	// если все ключи равны, строки сравниваются целиком
+	result := strings.Compare(item1.line, item2.line)
+	if s.reverse {
+		result = -result
+	}
+	return result
+}
+
+// sortContext - класс контекст для сортировщиков
+type sortContext struct {
+	sort iSorter
+}
+
+// sortLines - метод для вызова внутреннего метода сортировщика sortLines
+func (c *sortContext) sortLines(s *[]string) {
+	c.sort.sortSlice(s)
+}
+
+// checkSortedLines - метод для вызова внутреннего метода сортировщика checkSlice
+func (c *sortContext) checkSortedLines(s *[]string) int {
+	return c.sort.checkSlice(s)
+}
+
+// checkBatchSize - количество строк, проверяемых за один вызов checkSortedLines
+const checkBatchSize = 4096
+
+// checkSortedInput - метод для потоковой проверки сортировки входных данных
+// возвращает номер первой строки, нарушающей порядок, начиная с 1, и саму строку, либо 0, если данные отсортированы
+func (c *sortContext) checkSortedInput(input io.Reader, split bufio.SplitFunc) (int, string, error) {
+	scanner := newScanner(input, split)
+
+	// каждая порция начинается с последней строки предыдущей, чтобы проверить стык между ними
+	batch := make([]string, 0, checkBatchSize+1)
+	offset := 0 // номер строки, предшествующей порции
+	for {
+		for len(batch) <= checkBatchSize && scanner.Scan() {
+			batch = append(batch, scanner.Text())
+		}
+		if err := scanner.Err(); err != nil {
+			return 0, "", err
+		}
+		if index := c.checkSortedLines(&batch); index != -1 {
+			return offset + index + 1, batch[index], nil
+		}
+		if len(batch) <= checkBatchSize {
+			return 0, "", nil
+		}
+		offset += len(batch) - 1
+		batch = append(batch[:0], batch[len(batch)-1])
+	}
+}
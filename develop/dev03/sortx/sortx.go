@@ -0,0 +1,189 @@
+// Package sortx - библиотека для сортировки строк в стиле GNU sort:
+// ключи с модификаторами, стратегии сравнения, внешняя и параллельная сортировка, слияние и проверка
+package sortx
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"unicode/utf8"
+
+	"golang.org/x/text/language"
+)
+
+// Options - параметры сортировки
+type Options struct {
+	Keys       []KeySpec // ключи сортировки, если не указаны, строка сравнивается целиком
+	Modifiers  string    // глобальные модификаторы для ключей без собственных модификаторов, как у KeySpec
+	Separator  string    // символ-разделитель полей, пустая строка - поля разделяются пробелами
+	CSV        bool      // разбор записей по RFC 4180, Separator задает разделитель вместо запятой
+	Locale     string    // язык для сравнения текста, например ru, пустая строка - побайтовое сравнение
+	Seed       uint64    // seed для модификатора R, 0 - случайный
+	Unique     bool      // вывод только первой из строк с равными ключами
+	Dups       bool      // вывод только строк с повторяющимися ключами с их количеством, заменяет Unique
+	Stable     bool      // сохранение исходного порядка строк с равными ключами
+	Parallel   int       // количество горутин для сортировки, 0 или 1 - последовательная сортировка
+	BufferSize int64     // размер буфера для сортировки в памяти в байтах, 0 - размер по умолчанию
+	TempDir    string    // директория для временных файлов, пустая строка - os.TempDir()
+}
+
+// newSorter - функция для создания сортировщика и функции разделения входных данных по параметрам
+func newSorter(opts Options) (*sorter, bufio.SplitFunc, error) {
+	// выбор стратегии сравнения текста
+	var textComparer iComparer = &alphabeticalSorter{}
+	if opts.Locale != "" {
+		tag, err := language.Parse(opts.Locale)
+		if err != nil {
+			return nil, nil, err
+		}
+		textComparer = newCollationSorter(tag)
+	}
+
+	seed := opts.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+
+	// стратегии сравнения, выбираемые модификаторами
+	comparers := map[rune]iComparer{
+		'n': &numericalSorter{},
+		'h': &humanNumericalSorter{},
+		'M': &monthSorter{},
+		'g': &generalNumericalSorter{},
+		'V': &versionSorter{},
+		'R': &randomSorter{seed: seed},
+	}
+
+	// получение параметров сортировки по умолчанию из глобальных модификаторов
+	defaultKey := sortKey{comparer: textComparer}
+	if err := defaultKey.applyModifiers(opts.Modifiers, comparers); err != nil {
+		return nil, nil, err
+	}
+
+	// получение ключей сортировки, если ключи не указаны, строка сравнивается целиком с глобальными параметрами
+	keys := make([]sortKey, 0, len(opts.Keys))
+	for _, spec := range opts.Keys {
+		key, err := newSortKey(spec, defaultKey, textComparer, comparers)
+		if err != nil {
+			return nil, nil, err
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		keys = append(keys, defaultKey)
+	}
+
+	// выбор стратегии разделения строк на поля
+	var splitter iSplitter = &blankSplitter{}
+	split := scanLines
+	if opts.Separator != "" && utf8.RuneCountInString(opts.Separator) != 1 {
+		return nil, nil, errors.New("field separator must be a single character")
+	}
+	if opts.Separator != "" {
+		splitter = &delimiterSplitter{delimiter: opts.Separator}
+	}
+	if opts.CSV {
+		comma := ','
+		if opts.Separator != "" {
+			comma, _ = utf8.DecodeRuneInString(opts.Separator)
+		}
+		splitter = &csvSplitter{comma: comma}
+		split = scanCSVRecords
+	}
+
+	return &sorter{
+		keys:     keys,
+		splitter: splitter,
+		reverse:  defaultKey.reverse,
+		unique:   opts.Unique && !opts.Dups,
+		// как и в GNU sort, при -u строки с равными ключами не сравниваются целиком
+		stable:  opts.Stable || opts.Unique || opts.Dups,
+		workers: max(opts.Parallel, 1),
+	}, split, nil
+}
+
+// newExternalSorter - функция для создания сортировщика с выгрузкой во временные файлы
+func newExternalSorter(opts Options) (*externalSorter, error) {
+	lineSorter, split, err := newSorter(opts)
+	if err != nil {
+		return nil, err
+	}
+	fileSorter := &externalSorter{
+		context:    &sortContext{sort: lineSorter},
+		bufferSize: opts.BufferSize,
+		tempDir:    opts.TempDir,
+		unique:     opts.Unique && !opts.Dups,
+		dups:       opts.Dups,
+		split:      split,
+	}
+	if fileSorter.bufferSize <= 0 {
+		fileSorter.bufferSize = defaultBufferSize
+	}
+	if fileSorter.tempDir == "" {
+		fileSorter.tempDir = os.TempDir()
+	}
+	return fileSorter, nil
+}
+
+// NewComparator - функция для создания функции сравнения двух строк с учетом ключей и модификаторов
+func NewComparator(opts Options) (func(line1, line2 string) int, error) {
+	lineSorter, _, err := newSorter(opts)
+	if err != nil {
+		return nil, err
+	}
+	return lineSorter.compareLines, nil
+}
+
+// Sort - функция для сортировки строк из r с записью результата в w
+func Sort(r io.Reader, w io.Writer, opts Options) error {
+	return SortReaders([]io.Reader{r}, w, opts)
+}
+
+// SortReaders - функция для сортировки строк из нескольких источников, как если бы они были объединены
+// запись в w начинается только после чтения всех источников
+func SortReaders(inputs []io.Reader, w io.Writer, opts Options) error {
+	fileSorter, err := newExternalSorter(opts)
+	if err != nil {
+		return err
+	}
+	defer fileSorter.cleanup()
+
+	// чтение строк с выгрузкой отсортированных частей во временные файлы
+	lines, err := fileSorter.readRuns(inputs)
+	if err != nil {
+		return err
+	}
+	if len(fileSorter.runs) > 0 {
+		return fileSorter.mergeRuns(w)
+	}
+	fileSorter.context.sortLines(&lines)
+	output := fileSorter.newGroupWriter(w, fileSorter.dups)
+	for _, line := range lines {
+		if err = output.writeLine(line); err != nil {
+			return err
+		}
+	}
+	return output.flush()
+}
+
+// Merge - функция для потокового слияния уже отсортированных источников
+func Merge(inputs []io.Reader, w io.Writer, opts Options) error {
+	fileSorter, err := newExternalSorter(opts)
+	if err != nil {
+		return err
+	}
+	return fileSorter.mergeReaders(inputs, fileSorter.newGroupWriter(w, fileSorter.dups))
+}
+
+// Check - функция для потоковой проверки сортировки строк из r
+// возвращает номер первой строки, нарушающей порядок, начиная с 1, и саму строку, либо 0, если строки отсортированы
+func Check(r io.Reader, opts Options) (int, string, error) {
+	lineSorter, split, err := newSorter(opts)
+	if err != nil {
+		return 0, "", err
+	}
+	context := &sortContext{sort: lineSorter}
+	return context.checkSortedInput(r, split)
+}
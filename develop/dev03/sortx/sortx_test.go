@@ -0,0 +1,386 @@
+package sortx
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// generateLines - функция для генерации строк с повторяющимися ключами
+func generateLines(count int) []string {
+	random := rand.New(rand.NewSource(1))
+	lines := make([]string, count)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("key%d %d payload%d", random.Intn(100), random.Intn(1000), random.Intn(10))
+	}
+	return lines
+}
+
+type parallelTest struct {
+	name   string
+	sorter *sorter
+}
+
+var parallelTests = []parallelTest{
+	{"whole line", &sorter{keys: []sortKey{{comparer: &alphabeticalSorter{}}}, splitter: &blankSplitter{}}},
+	{"numerical key", &sorter{keys: []sortKey{{lowerColumn: 1, higherColumn: 2, comparer: &numericalSorter{}}}, splitter: &blankSplitter{}}},
+	{"stable", &sorter{keys: []sortKey{{higherColumn: 1, comparer: &alphabeticalSorter{}}}, splitter: &blankSplitter{}, stable: true}},
+	{"stable unique", &sorter{keys: []sortKey{{higherColumn: 1, comparer: &alphabeticalSorter{}}}, splitter: &blankSplitter{}, stable: true, unique: true}},
+	{"reverse unique", &sorter{keys: []sortKey{{comparer: &alphabeticalSorter{}, reverse: true}}, splitter: &blankSplitter{}, reverse: true, unique: true}},
+}
+
+func TestParallelSortMatchesSerial(t *testing.T) {
+	lines := generateLines(50000)
+	for _, test := range parallelTests {
+		serial := slices.Clone(lines)
+		test.sorter.sortSlice(&serial)
+
+		parallel := slices.Clone(lines)
+		parallelSorter := *test.sorter
+		parallelSorter.workers = 4
+		parallelSorter.sortSlice(&parallel)
+
+		if !slices.Equal(serial, parallel) {
+			t.Errorf("%s: parallel output differs from serial output", test.name)
+		}
+	}
+}
+
+func BenchmarkSortSerial(b *testing.B) {
+	lines := generateLines(200000)
+	lineSorter := parallelTests[1].sorter
+	for i := 0; i < b.N; i++ {
+		s := slices.Clone(lines)
+		lineSorter.sortSlice(&s)
+	}
+}
+
+func BenchmarkSortParallel(b *testing.B) {
+	lines := generateLines(200000)
+	lineSorter := *parallelTests[1].sorter
+	lineSorter.workers = 8
+	for i := 0; i < b.N; i++ {
+		s := slices.Clone(lines)
+		lineSorter.sortSlice(&s)
+	}
+}
+
+type checkTest struct {
+	input   string
+	unique  bool
+	lineNum int
+	line    string
+}
+
+var checkTests = []checkTest{
+	{"a\nb\nc\n", false, 0, ""},
+	{"a\nc\nb\n", false, 3, "b"},
+	{"a\na\nb\n", false, 0, ""},
+	{"a\na\nb\n", true, 2, "a"},
+	{strings.Repeat("a\n", checkBatchSize) + "b\na\n", false, checkBatchSize + 2, "a"},
+}
+
+func TestCheckSortedInput(t *testing.T) {
+	for _, test := range checkTests {
+		context := &sortContext{sort: &sorter{keys: []sortKey{{comparer: &alphabeticalSorter{}}}, splitter: &blankSplitter{}, unique: test.unique}}
+		lineNum, line, err := context.checkSortedInput(strings.NewReader(test.input), bufio.ScanLines)
+		if err != nil || lineNum != test.lineNum || line != test.line {
+			t.Errorf("Output %v, %q was not equal to expected %v, %q", lineNum, line, test.lineNum, test.line)
+		}
+	}
+}
+
+type sortTest struct {
+	name   string
+	input  string
+	opts   Options
+	output string
+}
+
+var sortTests = []sortTest{
+	{"alphabetical", "b\nc\na\n", Options{}, "a\nb\nc\n"},
+	{"alphabetical reverse", "b\nc\na\n", Options{Modifiers: "r"}, "c\nb\na\n"},
+	{"fold case", "b\nA\na\nB\n", Options{Modifiers: "f"}, "A\na\nB\nb\n"},
+	{"collation", "ж\nё\nе\nя\n", Options{Locale: "ru"}, "е\nё\nж\nя\n"},
+	{"numerical", "10\n9\n-1\nx\n", Options{Modifiers: "n"}, "-1\nx\n9\n10\n"},
+	{"general numerical", "1e3\nnan\n\n-inf\n2.5\n", Options{Modifiers: "g"}, "\nnan\n-inf\n2.5\n1e3\n"},
+	{"version", "v1.10\nv1.9\nv1.2\n", Options{Modifiers: "V"}, "v1.2\nv1.9\nv1.10\n"},
+	{"month", "March\nJanuary\nfoo\nFebruary\n", Options{Modifiers: "M"}, "foo\nJanuary\nFebruary\nMarch\n"},
+	{"human numerical", "2G\n10K\n1.5M\n-1K\n", Options{Modifiers: "h"}, "-1K\n10K\n1.5M\n2G\n"},
+	{"numerical fraction", "10\n2.5\n-1\n", Options{Modifiers: "n"}, "-1\n2.5\n10\n"},
+	{"version leading zeros", "v1.2\nv1.02\n", Options{Modifiers: "V"}, "v1.02\nv1.2\n"},
+	{"human numerical negative", "-1K\n900\n-2M\n", Options{Modifiers: "h"}, "-2M\n-1K\n900\n"},
+	{"human numerical iec", "1GiB\n512MiB\n0.5Ki\n", Options{Modifiers: "h"}, "0.5Ki\n512MiB\n1GiB\n"},
+	{"collation fold case", "Б\nа\nВ\n", Options{Locale: "ru", Modifiers: "f"}, "а\nБ\nВ\n"},
+	{"key to end of line", "a x 2\nb x 1\n", Options{Keys: []KeySpec{{StartField: 2}}}, "b x 1\na x 2\n"},
+	{"empty fields", "a::2\nb::1\n", Options{Keys: []KeySpec{{StartField: 3, EndField: 3}}, Separator: ":"}, "b::1\na::2\n"},
+	{"numerical key", "a 3\nb 1\nc 2\n", Options{Keys: []KeySpec{{StartField: 2, EndField: 2, Modifiers: "n"}}}, "b 1\nc 2\na 3\n"},
+	{"key chain", "b 1\na 1\nc 0\n", Options{Keys: []KeySpec{{StartField: 2, EndField: 2, Modifiers: "nr"}, {StartField: 1, EndField: 1}}}, "a 1\nb 1\nc 0\n"},
+	{"stable", "x b\ny a\nx a\n", Options{Keys: []KeySpec{{StartField: 1, EndField: 1}}, Stable: true}, "x b\nx a\ny a\n"},
+	{"unique", "b\na\nb\na\n", Options{Unique: true}, "a\nb\n"},
+	{"unique by key", "a 2\nb 1\nc 2\n", Options{Keys: []KeySpec{{StartField: 2, EndField: 2}}, Unique: true}, "b 1\na 2\n"},
+	{"unique by numeric key", "x 01\ny 1\nz 2\n", Options{Keys: []KeySpec{{StartField: 2, Modifiers: "n"}}, Unique: true}, "x 01\nz 2\n"},
+	{"unique external", "a 2\nb 1\nc 2\nd 1\n", Options{Keys: []KeySpec{{StartField: 2}}, Unique: true, BufferSize: 1}, "b 1\na 2\n"},
+	{"dups", "a 2\nb 1\nc 2\nd 3\ne 2\n", Options{Keys: []KeySpec{{StartField: 2}}, Dups: true}, "      3 a 2\n"},
+	{"dups external", "a 2\nb 1\nc 2\nd 1\ne 2\n", Options{Keys: []KeySpec{{StartField: 2}}, Dups: true, BufferSize: 1}, "      2 b 1\n      3 a 2\n"},
+	{"separator", "a:2\nb:1\n", Options{Keys: []KeySpec{{StartField: 2, EndField: 2}}, Separator: ":"}, "b:1\na:2\n"},
+	{"csv", "\"x,y\",2\nz,1\n", Options{Keys: []KeySpec{{StartField: 2, EndField: 2}}, CSV: true}, "z,1\n\"x,y\",2\n"},
+	{"char offsets", "a 2024-03-01T10\nb 2023-12-31T23\nc 2024-01-15T08\n", Options{Keys: []KeySpec{{StartField: 2, StartChar: 7, EndField: 2, EndChar: 8}}}, "c 2024-01-15T08\na 2024-03-01T10\nb 2023-12-31T23\n"},
+	{"char offsets with blanks", "x   b9\ny a5\n", Options{Keys: []KeySpec{{StartField: 2, StartChar: 2, EndField: 2, EndChar: 2, Modifiers: "bn"}}}, "y a5\nx   b9\n"},
+	{"char offsets without blanks", "x   b1\ny a5\n", Options{Keys: []KeySpec{{StartField: 2, StartChar: 2, EndField: 2, EndChar: 2}}}, "x   b1\ny a5\n"},
+	{"char offsets across fields", "a:xy:12\nb:xz:01\n", Options{Keys: []KeySpec{{StartField: 2, StartChar: 2, EndField: 3, EndChar: 1}}, Separator: ":"}, "a:xy:12\nb:xz:01\n"},
+	{"char offsets utf8", "яб\nяа\n", Options{Keys: []KeySpec{{StartField: 1, StartChar: 2}}}, "яа\nяб\n"},
+}
+
+func TestSort(t *testing.T) {
+	for _, test := range sortTests {
+		output := &strings.Builder{}
+		err := Sort(strings.NewReader(test.input), output, test.opts)
+		if err != nil || output.String() != test.output {
+			t.Errorf("%s: Output %q, %v was not equal to expected %q", test.name, output.String(), err, test.output)
+		}
+	}
+}
+
+func TestRandomSortGroupsEqualKeys(t *testing.T) {
+	output := &strings.Builder{}
+	err := Sort(strings.NewReader("a\nb\na\nc\nb\na\n"), output, Options{Modifiers: "R", Seed: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// одинаковые ключи должны идти подряд
+	lines := strings.Fields(output.String())
+	seen := map[string]bool{}
+	for i, line := range lines {
+		if seen[line] && lines[i-1] != line {
+			t.Errorf("Output %q does not group equal keys", output.String())
+		}
+		seen[line] = true
+	}
+}
+
+func TestMerge(t *testing.T) {
+	inputs := []io.Reader{strings.NewReader("a\nc\ne\n"), strings.NewReader("b\nc\nd\n")}
+	output := &strings.Builder{}
+	err := Merge(inputs, output, Options{})
+	if err != nil || output.String() != "a\nb\nc\nc\nd\ne\n" {
+		t.Errorf("Output %q, %v was not equal to expected %q", output.String(), err, "a\nb\nc\nc\nd\ne\n")
+	}
+}
+
+func TestMergeDups(t *testing.T) {
+	inputs := []io.Reader{strings.NewReader("a\nc\n"), strings.NewReader("a\nb\n")}
+	output := &strings.Builder{}
+	err := Merge(inputs, output, Options{Dups: true})
+	if err != nil || output.String() != "      2 a\n" {
+		t.Errorf("Output %q, %v was not equal to expected %q", output.String(), err, "      2 a\n")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	lineNum, line, err := Check(strings.NewReader("1\n2\n10\n3\n"), Options{Modifiers: "n"})
+	if err != nil || lineNum != 4 || line != "3" {
+		t.Errorf("Output %v, %q was not equal to expected %v, %q", lineNum, line, 4, "3")
+	}
+}
+
+type parseKeyTest struct {
+	spec string
+	key  KeySpec
+	err  bool
+}
+
+var parseKeyTests = []parseKeyTest{
+	{"2", KeySpec{StartField: 2}, false},
+	{"2,3", KeySpec{StartField: 2, EndField: 3}, false},
+	{"2n,2r", KeySpec{StartField: 2, EndField: 2, Modifiers: "nr"}, false},
+	{"2.3,2.5", KeySpec{StartField: 2, StartChar: 3, EndField: 2, EndChar: 5}, false},
+	{"3.2b,3n", KeySpec{StartField: 3, StartChar: 2, EndField: 3, Modifiers: "bn"}, false},
+	{"2.0", KeySpec{}, true},
+	{"2.x", KeySpec{}, true},
+	{"x", KeySpec{}, true},
+	{"1,2,3", KeySpec{}, true},
+	{"1z", KeySpec{}, true},
+}
+
+func TestParseKey(t *testing.T) {
+	for _, test := range parseKeyTests {
+		key, err := ParseKey(test.spec)
+		if (err != nil) != test.err || (!test.err && key != test.key) {
+			t.Errorf("Output %v, %v was not equal to expected %v for %q", key, err, test.key, test.spec)
+		}
+	}
+}
+
+type externalTest struct {
+	name string
+	opts Options
+}
+
+var externalTests = []externalTest{
+	{"whole line", Options{}},
+	// при стабильной сортировке равные строки из разных временных файлов должны сохранить исходный порядок
+	{"stable", Options{Keys: []KeySpec{{StartField: 1, EndField: 1}}, Stable: true}},
+}
+
+func TestExternalSortMatchesInMemory(t *testing.T) {
+	input := strings.Join(generateLines(20000), "\n")
+	for _, test := range externalTests {
+		expected := &strings.Builder{}
+		if err := Sort(strings.NewReader(input), expected, test.opts); err != nil {
+			t.Fatal(err)
+		}
+		// маленький буфер дает больше maxMergeRuns временных файлов и слияние в несколько проходов
+		opts := test.opts
+		opts.BufferSize, opts.TempDir = 4<<10, t.TempDir()
+		output := &strings.Builder{}
+		err := Sort(strings.NewReader(input), output, opts)
+		if err != nil || output.String() != expected.String() {
+			t.Errorf("%s: Output of external sort, %v was not equal to in-memory sort", test.name, err)
+		}
+	}
+}
+
+type crlfTest struct {
+	name   string
+	input  string
+	opts   Options
+	output string
+}
+
+var crlfTests = []crlfTest{
+	{"lines", "b\r\nc\r\na\r\n", Options{}, "a\r\nb\r\nc\r\n"},
+	{"numerical key", "x 3\r\ny 1\r\nz 2\r\n", Options{Keys: []KeySpec{{StartField: 2, Modifiers: "n"}}}, "y 1\r\nz 2\r\nx 3\r\n"},
+	{"external", "b\r\nc\r\na\r\n", Options{BufferSize: 1}, "a\r\nb\r\nc\r\n"},
+	{"csv", "\"x\r\ny\",2\r\nz,1\r\n", Options{Keys: []KeySpec{{StartField: 2, EndField: 2, Modifiers: "n"}}, CSV: true}, "z,1\r\n\"x\r\ny\",2\r\n"},
+}
+
+func TestSortKeepsCRLF(t *testing.T) {
+	for _, test := range crlfTests {
+		test.opts.TempDir = t.TempDir()
+		output := &strings.Builder{}
+		err := Sort(strings.NewReader(test.input), output, test.opts)
+		if err != nil || output.String() != test.output {
+			t.Errorf("%s: Output %q, %v was not equal to expected %q", test.name, output.String(), err, test.output)
+		}
+	}
+
+	// слияние тоже сохраняет CR
+	inputs := []io.Reader{strings.NewReader("a\r\nc\r\n"), strings.NewReader("b\r\n")}
+	output := &strings.Builder{}
+	err := Merge(inputs, output, Options{})
+	if err != nil || output.String() != "a\r\nb\r\nc\r\n" {
+		t.Errorf("Output %q, %v was not equal to expected %q", output.String(), err, "a\r\nb\r\nc\r\n")
+	}
+}
+
+func TestSortLongLines(t *testing.T) {
+	// строки длиннее буфера bufio.Scanner по умолчанию в 64 КиБ
+	long := strings.Repeat("b", 70000)
+	input := long + "\na\n" + long + "c\n"
+	expected := "a\n" + long + "\n" + long + "c\n"
+	for _, opts := range []Options{{}, {BufferSize: 1, TempDir: t.TempDir()}} {
+		output := &strings.Builder{}
+		err := Sort(strings.NewReader(input), output, opts)
+		if err != nil || output.String() != expected {
+			t.Errorf("Output of %v bytes, %v was not equal to expected %v bytes", output.Len(), err, len(expected))
+		}
+	}
+	lineNum, _, err := Check(strings.NewReader(input), Options{})
+	if err != nil || lineNum != 2 {
+		t.Errorf("Output %v, %v was not equal to expected %v", lineNum, err, 2)
+	}
+}
+
+func TestSortReadersConcatenatesInputs(t *testing.T) {
+	// последняя строка файла без перевода строки не склеивается с первой строкой следующего
+	inputs := []io.Reader{strings.NewReader("c\na"), strings.NewReader("b\n"), strings.NewReader("")}
+	output := &strings.Builder{}
+	err := SortReaders(inputs, output, Options{})
+	if err != nil || output.String() != "a\nb\nc\n" {
+		t.Errorf("Output %q, %v was not equal to expected %q", output.String(), err, "a\nb\nc\n")
+	}
+}
+
+func TestMergeKeepsInputOrder(t *testing.T) {
+	// равные по ключу строки выводятся в порядке входных файлов
+	inputs := []io.Reader{strings.NewReader("a 1\nc 1\ne 1\n"), strings.NewReader("b 2\nc 2\nd 2")}
+	output := &strings.Builder{}
+	err := Merge(inputs, output, Options{Keys: []KeySpec{{StartField: 1, EndField: 1}}, Stable: true})
+	expected := "a 1\nb 2\nc 1\nc 2\nd 2\ne 1\n"
+	if err != nil || output.String() != expected {
+		t.Errorf("Output %q, %v was not equal to expected %q", output.String(), err, expected)
+	}
+}
+
+func TestScanCSVRecords(t *testing.T) {
+	scanner := bufio.NewScanner(strings.NewReader("a,\"b\nc\"\nd,e"))
+	scanner.Split(scanCSVRecords)
+	records := make([]string, 0)
+	for scanner.Scan() {
+		records = append(records, scanner.Text())
+	}
+	expected := []string{"a,\"b\nc\"", "d,e"}
+	if !slices.Equal(records, expected) {
+		t.Errorf("Output %q was not equal to expected %q", records, expected)
+	}
+}
+
+type filesTest struct {
+	name   string
+	merge  bool
+	inputs []string // содержимое входных файлов, первый из них совпадает с файлом результата
+	output string
+}
+
+var filesTests = []filesTest{
+	{"sort", false, []string{"c\na\n", "b\n"}, "a\nb\nc\n"},
+	{"sort empty", false, []string{""}, ""},
+	{"merge", true, []string{"a\nc\n", "b\nd\n"}, "a\nb\nc\nd\n"},
+	{"merge single", true, []string{"a\nb\n"}, "a\nb\n"},
+	// входной файл больше буфера чтения, поэтому он не успевает прочитаться до создания файла результата
+	{"merge large", true, []string{strings.Repeat("a\n", initialScanBufferSize), "b\n"}, strings.Repeat("a\n", initialScanBufferSize) + "b\n"},
+}
+
+func TestFilesOutputIsInput(t *testing.T) {
+	for _, test := range filesTests {
+		dir := t.TempDir()
+		paths := make([]string, len(test.inputs))
+		for i, input := range test.inputs {
+			paths[i] = filepath.Join(dir, fmt.Sprintf("input%d.txt", i))
+			if err := os.WriteFile(paths[i], []byte(input), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		var err error
+		opts := Options{TempDir: dir}
+		if test.merge {
+			err = MergeFiles(paths, paths[0], opts)
+		} else {
+			err = SortFiles(paths, paths[0], opts)
+		}
+		output, readErr := os.ReadFile(paths[0])
+		if err != nil || readErr != nil || string(output) != test.output {
+			t.Errorf("%s: Output %q, %v, %v was not equal to expected %q", test.name, output, err, readErr, test.output)
+		}
+	}
+}
+
+func TestFilesMissingInput(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "output.txt")
+	// при ошибке открытия входного файла файл результата не создается
+	for _, sortFiles := range []func([]string, string, Options) error{SortFiles, MergeFiles} {
+		err := sortFiles([]string{filepath.Join(dir, "missing.txt")}, output, Options{})
+		if _, statErr := os.Stat(output); err == nil || !os.IsNotExist(statErr) {
+			t.Errorf("Output %v, %v was not equal to expected open error without output file", err, statErr)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package sortx
+
+import (
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// alphabeticalSorter - конкретная стратегия сравнения ключей в алфавитном порядке
+type alphabeticalSorter struct{}
+
+// compareKeys - реализация метода compareKeys интерфейса iComparer классом alphabeticalSorter
+func (a *alphabeticalSorter) compareKeys(key1, key2 string) int {
+	return strings.Compare(key1, key2)
+}
+
+// collationSorter - конкретная стратегия сравнения ключей по правилам языка, например ё рядом с е
+type collationSorter struct {
+	collators sync.Pool // collate.Collator не безопасен для одновременного использования
+}
+
+// newCollationSorter - функция для создания стратегии сравнения для указанного языка
+func newCollationSorter(tag language.Tag) *collationSorter {
+	return &collationSorter{
+		collators: sync.Pool{
+			New: func() any {
+				return collate.New(tag)
+			},
+		},
+	}
+}
+
+// compareKeys - реализация метода compareKeys интерфейса iComparer классом collationSorter
+func (c *collationSorter) compareKeys(key1, key2 string) int {
+	collator := c.collators.Get().(*collate.Collator)
+	defer c.collators.Put(collator)
+	return collator.CompareString(key1, key2)
+}
+
+// numericalSorter - конкретная стратегия сравнения ключей в числовом порядке
+type numericalSorter struct{}
+
+// parseNumericalKey - функция для конвертации числа в начале ключа в float64
+// как и в GNU sort, ключ без числа в начале считается нулем
+func parseNumericalKey(key string) float64 {
+	key = strings.TrimLeft(key, blanks)
+	// поиск конца числа с необязательным знаком и дробной частью
+	end := 0
+	if end < len(key) && (key[end] == '-' || key[end] == '+') {
+		end++
+	}
+	for end < len(key) && key[end] >= '0' && key[end] <= '9' {
+		end++
+	}
+	if end < len(key) && key[end] == '.' {
+		end++
+		for end < len(key) && key[end] >= '0' && key[end] <= '9' {
+			end++
+		}
+	}
+	number, err := strconv.ParseFloat(key[:end], 64)
+	if err != nil {
+		return 0
+	}
+	return number
+}
+
+// compareKeys - реализация метода compareKeys интерфейса iComparer классом numericalSorter
+func (n *numericalSorter) compareKeys(key1, key2 string) int {
+	return cmp.Compare(parseNumericalKey(key1), parseNumericalKey(key2))
+}
+
+// generalNumericalSorter - конкретная стратегия сравнения ключей как чисел с плавающей точкой
+type generalNumericalSorter struct{}
+
+// floatPrefixRegexp - regexp для поиска числа с плавающей точкой в начале ключа, включая экспоненту, inf и nan
+var floatPrefixRegexp = regexp.MustCompile(`^[-+]?(?i:inf(?:inity)?|nan|(?:[0-9]+\.?[0-9]*|\.[0-9]+)(?:e[-+]?[0-9]+)?)`)
+
+// floatKey - разобранный ключ для стратегии generalNumericalSorter
+type floatKey struct {
+	class int // 0 - не число или пустой ключ, 1 - NaN, 2 - число, включая бесконечности
+	value float64
+}
+
+// parseFloatKey - функция для конвертации ключа в floatKey
+func parseFloatKey(key string) floatKey {
+	number, err := strconv.ParseFloat(floatPrefixRegexp.FindString(strings.TrimLeft(key, blanks)), 64)
+	switch {
+	case err != nil && !errors.Is(err, strconv.ErrRange):
+		return floatKey{class: 0}
+	case math.IsNaN(number):
+		return floatKey{class: 1}
+	}
+	return floatKey{class: 2, value: number}
+}
+
+// compareKeys - реализация метода compareKeys интерфейса iComparer классом generalNumericalSorter
+// как и в GNU sort, нечисловые и пустые ключи идут первыми, затем NaN, затем числа от -inf до +inf
+func (g *generalNumericalSorter) compareKeys(key1, key2 string) int {
+	float1, float2 := parseFloatKey(key1), parseFloatKey(key2)
+	if result := cmp.Compare(float1.class, float2.class); result != 0 {
+		return result
+	}
+	return cmp.Compare(float1.value, float2.value)
+}
+
+// versionSorter - конкретная стратегия сравнения ключей как номеров версий, например v1.9 < v1.10
+type versionSorter struct{}
+
+// splitDigits - функция для получения длины начальной части строки, состоящей только из цифр или только из не цифр
+func splitDigits(s string) int {
+	isDigit := s[0] >= '0' && s[0] <= '9'
+	end := 1
+	for end < len(s) && (s[end] >= '0' && s[end] <= '9') == isDigit {
+		end++
+	}
+	return end
+}
+
+// compareKeys - реализация метода compareKeys интерфейса iComparer классом versionSorter
+// ключи разбиваются на чередующиеся числовые и нечисловые части, числовые части сравниваются как числа
+func (v *versionSorter) compareKeys(key1, key2 string) int {
+	for key1 != "" && key2 != "" {
+		end1, end2 := splitDigits(key1), splitDigits(key2)
+		part1, part2 := key1[:end1], key2[:end2]
+		key1, key2 = key1[end1:], key2[end2:]
+
+		isDigit1, isDigit2 := part1[0] >= '0' && part1[0] <= '9', part2[0] >= '0' && part2[0] <= '9'
+		if isDigit1 != isDigit2 {
+			// число идет раньше текста
+			if isDigit1 {
+				return -1
+			}
+			return 1
+		}
+		if isDigit1 {
+			// сравнение чисел произвольной длины без ведущих нулей: сначала по длине, затем посимвольно
+			part1, part2 = strings.TrimLeft(part1, "0"), strings.TrimLeft(part2, "0")
+			if result := cmp.Compare(len(part1), len(part2)); result != 0 {
+				return result
+			}
+		}
+		if result := strings.Compare(part1, part2); result != 0 {
+			return result
+		}
+	}
+	return cmp.Compare(len(key1), len(key2))
+}
+
+// randomSorter - конкретная стратегия сравнения ключей в случайном порядке с группировкой одинаковых ключей
+type randomSorter struct {
+	seed uint64
+}
+
+// hashKey - метод для получения хэша ключа, зависящего от seed
+func (r *randomSorter) hashKey(key string) uint64 {
+	hash := fnv.New64a()
+	_ = binary.Write(hash, binary.LittleEndian, r.seed)
+	_, _ = hash.Write([]byte(key))
+	return hash.Sum64()
+}
+
+// compareKeys - реализация метода compareKeys интерфейса iComparer классом randomSorter
+// порядок определяется хэшем ключа, поэтому одинаковые ключи всегда оказываются рядом
+func (r *randomSorter) compareKeys(key1, key2 string) int {
+	if result := cmp.Compare(r.hashKey(key1), r.hashKey(key2)); result != 0 {
+		return result
+	}
+	return strings.Compare(key1, key2)
+}
+
+// monthSorter - конкретная стратегия сравнения ключей в порядке месяцев
+type monthSorter struct{}
+
+// parseMonthKey - функция для конвертации первого слова ключа в тип time.Month
+func parseMonthKey(key string) time.Month {
+	words := strings.Fields(key)
+	// отсутствующий ключ считается меньше любого месяца
+	if len(words) == 0 {
+		return 0
+	}
+	dateWord, err := time.Parse("January", words[0])
+	// как и в GNU sort, ключ, не являющийся месяцем, считается меньше любого месяца
+	if err != nil {
+		return 0
+	}
+	return dateWord.Month()
+}
+
+// compareKeys - реализация метода compareKeys интерфейса iComparer классом monthSorter
+func (m *monthSorter) compareKeys(key1, key2 string) int {
+	return cmp.Compare(parseMonthKey(key1), parseMonthKey(key2))
+}
+
+// humanNumericalSorter - конкретная стратегия сравнения ключей как размеров с суффиксами, например 1.5K, 2G, 10MiB
+type humanNumericalSorter struct{}
+
+// humanNumberRegexp - regexp для поиска числа с необязательными суффиксами SI (K, M, G) и IEC (Ki, MiB) в начале ключа
+var humanNumberRegexp = regexp.MustCompile(`^([-+]?(?:[0-9]+\.?[0-9]*|\.[0-9]+))([kKMGTPEZYRQ]?)(?:i?B)?`)
+
+// humanSuffixes - суффиксы в порядке возрастания порядка величины
+const humanSuffixes = "KMGTPEZYRQ"
+
+// humanKey - разобранный ключ для стратегии humanNumericalSorter
+type humanKey struct {
+	value     float64
+	magnitude int // номер суффикса в humanSuffixes, начиная с 1, 0 - без суффикса
+}
+
+// parseHumanNumericalKey - функция для конвертации ключа в humanKey
+// ключ без числа в начале считается нулем
+func parseHumanNumericalKey(key string) humanKey {
+	match := humanNumberRegexp.FindStringSubmatch(strings.TrimLeft(key, blanks))
+	if match == nil {
+		return humanKey{}
+	}
+	value, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return humanKey{}
+	}
+	parsed := humanKey{value: value}
+	if match[2] != "" {
+		parsed.magnitude = strings.Index(humanSuffixes, strings.ToUpper(match[2])) + 1
+	}
+	return parsed
+}
+
+// compareKeys - реализация метода compareKeys интерфейса iComparer классом humanNumericalSorter
+// как и в GNU sort, сначала сравниваются знаки, затем порядки величины по суффиксам, затем сами числа
+func (h *humanNumericalSorter) compareKeys(key1, key2 string) int {
+	human1, human2 := parseHumanNumericalKey(key1), parseHumanNumericalKey(key2)
+	sign1, sign2 := cmp.Compare(human1.value, 0), cmp.Compare(human2.value, 0)
+	if sign1 != sign2 || sign1 == 0 {
+		return cmp.Compare(sign1, sign2)
+	}
+	// у отрицательных чисел больший порядок означает меньшее число
+	if result := cmp.Compare(human1.magnitude, human2.magnitude) * sign1; result != 0 {
+		return result
+	}
+	return cmp.Compare(human1.value, human2.value)
+}
@@ -1,767 +1,271 @@
 package main
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"log"
 	"os"
-	"regexp"
 	"slices"
-	"sort"
 	"strconv"
 	"strings"
-	"time"
-)
-
-// iSorter - интерфейс для сортировщика
-type iSorter interface {
-	sortSlice(*[]string)
-	checkSlice(*[]string) bool
-}
-
-// sorter - основной класс сортировщика
-type sorter struct {
-	reverse              bool
-	unique               bool
-	removeTrailingSpaces bool
-	higherColumn         int
-	lowerColumn          int
-}
-
-// sortSlice - реализация метода sortSlice интерфейса iSorter классом sorter
-func (a *sorter) sortSlice(s *[]string) {
-	// удаление хвостовых пробелов на основании поля removeTrailingSpaces
-	if a.removeTrailingSpaces {
-		for _, line := range *s {
-			strings.TrimRight(line, " \n\r\t")
-		}
-	}
-	// разделение строк на поля
-	splitLines := make([][]string, 0)
-	for _, line := range *s {
-		splitLines = append(splitLines, strings.Fields(line))
-	}
-	// сортировка строк в алфавитном порядке на основании выбранных полей
-	sort.Slice(splitLines, func(i int, j int) bool {
-		lastCol := 0
-		for col := a.lowerColumn; col >= a.higherColumn; col++ {
-			lastCol = col
-			if splitLines[i][col] != splitLines[j][col] {
-				break
-			}
-		}
-		return splitLines[i][lastCol] < splitLines[j][lastCol]
-	})
 
-	// сборка полей обратно в строки
-	for key, splitLine := range splitLines {
-		(*s)[key] = strings.Join(splitLine, " ")
-	}
+	"example.com/dev03/sortx"
+)
 
-	// удаление повторяющихся строк на основании поля unique
-	if a.unique {
-		*s = slices.Compact(*s)
-	}
+// коды возврата, как у GNU sort
+const (
+	exitDisorder = 1 // при -c и -C строки не отсортированы
+	exitError    = 2 // произошла ошибка
+)
 
-	// разворот слайса на основании поля reverse
-	if a.reverse {
-		slices.Reverse(*s)
-	}
+// fatal - функция для вывода ошибки и завершения программы с кодом exitError
+// код 1 остается только для нарушения порядка, чтобы при проверке его можно было отличить от ошибки
+func fatal(v ...any) {
+	log.Println(v...)
+	os.Exit(exitError)
 }
 
-// checkSlice - реализация метода checkSlice интерфейса iSorter классом sorter
-func (a *sorter) checkSlice(s *[]string) bool {
-	// копирование слайса в новый
-	var sCopy []string
-	copy(sCopy, *s)
+// valueFlags - ключи, за которыми следует значение
+var valueFlags = []string{"-k", "-t", "-S", "-T", "-o"}
 
-	// удаление хвостовых пробелов на основании поля removeTrailingSpaces
-	if a.removeTrailingSpaces {
-		for _, line := range sCopy {
-			strings.TrimRight(line, " \n\r\t")
+// getInputFiles - функция для получения путей к входным файлам из аргументов командной строки
+// если файлы не указаны, возвращается "-", что означает чтение из stdin
+func getInputFiles(args []string) []string {
+	files := make([]string, 0)
+	for argIndex := 0; argIndex < len(args); argIndex++ {
+		arg := args[argIndex]
+		switch {
+		// пропуск значения ключа
+		case slices.Contains(valueFlags, arg):
+			argIndex++
+		case arg == "-" || !strings.HasPrefix(arg, "-"):
+			files = append(files, arg)
 		}
 	}
-	// разделение строк на поля
-	splitLines := make([][]string, 0)
-	for _, line := range sCopy {
-		splitLines = append(splitLines, strings.Fields(line))
+	if len(files) == 0 {
+		files = append(files, "-")
 	}
-	// проверка сортировки строк в алфавитном порядке на основании выбранных полей
-	return sort.SliceIsSorted(splitLines, func(i int, j int) bool {
-		lastCol := 0
-		for col := a.lowerColumn; col >= a.higherColumn; col++ {
-			lastCol = col
-			if splitLines[i][col] != splitLines[j][col] {
-				break
-			}
-		}
-		return splitLines[i][lastCol] < splitLines[j][lastCol]
-	})
-
-}
-
-// alphabeticalSorter - конкретный класс сортировщика
-type alphabeticalSorter struct {
-	sorter
+	return files
 }
 
-// sortSlice - реализация метода sortSlice интерфейса iSorter классом alphabeticalSorter
-func (a *alphabeticalSorter) sortSlice(s *[]string) {
-	// удаление хвостовых пробелов на основании поля removeTrailingSpaces
-	if a.removeTrailingSpaces {
-		for _, line := range *s {
-			strings.TrimRight(line, " \n\r\t")
-		}
-	}
-	// разделение строк на поля
-	splitLines := make([][]string, 0)
-	for _, line := range *s {
-		splitLines = append(splitLines, strings.Fields(line))
-	}
-	// сортировка строк в алфавитном порядке на основании выбранных полей
-	sort.Slice(splitLines, func(i int, j int) bool {
-		lastCol := 0
-		for col := a.lowerColumn; col >= a.higherColumn; col++ {
-			lastCol = col
-			if splitLines[i][col] != splitLines[j][col] {
-				break
+// getKeySpecs - функция для получения описаний ключей из всех ключей -k
+// как и в GNU sort, описание может идти отдельным аргументом (-k 2,2n) или сразу после ключа (-k2,2n)
+func getKeySpecs(args []string) ([]string, error) {
+	specs := make([]string, 0)
+	for argIndex := 0; argIndex < len(args); argIndex++ {
+		arg := args[argIndex]
+		switch {
+		case arg == "-k":
+			if len(args) <= argIndex+1 {
+				return nil, errors.New("not enough arguments given after -k flag")
 			}
+			argIndex++
+			specs = append(specs, args[argIndex])
+		case strings.HasPrefix(arg, "-k"):
+			specs = append(specs, strings.TrimPrefix(arg, "-k"))
+		// значение другого ключа не может быть ключом -k
+		case slices.Contains(valueFlags, arg):
+			argIndex++
 		}
-		return splitLines[i][lastCol] < splitLines[j][lastCol]
-	})
-
-	// сборка полей обратно в строки
-	for key, splitLine := range splitLines {
-		(*s)[key] = strings.Join(splitLine, " ")
-	}
-
-	// удаление повторяющихся строк на основании поля unique
-	if a.unique {
-		*s = slices.Compact(*s)
-	}
-
-	// разворот слайса на основании поля reverse
-	if a.reverse {
-		slices.Reverse(*s)
-	}
-
-}
-
-// checkSlice - реализация метода checkSlice интерфейса iSorter классом alphabeticalSorter
-func (a *alphabeticalSorter) checkSlice(s *[]string) bool {
-	// копирование слайса в новый
-	var sCopy []string
-	copy(sCopy, *s)
-
-	// удаление хвостовых пробелов на основании поля removeTrailingSpaces
-	if a.removeTrailingSpaces {
-		for _, line := range sCopy {
-			strings.TrimRight(line, " \n\r\t")
-		}
-	}
-	// разделение строк на поля
-	splitLines := make([][]string, 0)
-	for _, line := range sCopy {
-		splitLines = append(splitLines, strings.Fields(line))
 	}
-	// проверка сортировки строк в алфавитном порядке на основании выбранных полей
-	return sort.SliceIsSorted(splitLines, func(i int, j int) bool {
-		lastCol := 0
-		for col := a.lowerColumn; col >= a.higherColumn; col++ {
-			lastCol = col
-			if splitLines[i][col] != splitLines[j][col] {
-				break
-			}
-		}
-		return splitLines[i][lastCol] < splitLines[j][lastCol]
-	})
-
-}
-
-// numericalSorter - конкретный класс сортировщика
-type numericalSorter struct {
-	sorter
+	return specs, nil
 }
 
-// sortSlice - реализация метода sortSlice интерфейса iSorter классом numericalSorter
-func (n *numericalSorter) sortSlice(s *[]string) {
-	// удаление хвостовых пробелов на основании поля removeTrailingSpaces
-	if n.removeTrailingSpaces {
-		for _, line := range *s {
-			strings.TrimRight(line, " \n\r\t")
-		}
-	}
-	// разделение строк на поля c конвертацией строк в числа
-	splitLines := make([][]int, 0)
-	for _, line := range *s {
-		splitLine := strings.Fields(line)
-		intSplitLine := make([]int, 0)
-		for _, word := range splitLine {
-			intWord, err := strconv.Atoi(word)
-			if err != nil {
-				log.Fatalln("file contains non-numerical values")
-			}
-			intSplitLine = append(intSplitLine, intWord)
-		}
-		splitLines = append(splitLines, intSplitLine)
-	}
-	// сортировка строк в числовом порядке на основании выбранных полей
-	sort.Slice(splitLines, func(i int, j int) bool {
-		lastCol := 0
-		for col := n.lowerColumn; col >= n.higherColumn; col++ {
-			lastCol = col
-			if splitLines[i][col] != splitLines[j][col] {
-				break
+// getFlagValue - функция для получения значения ключа, например -t : или -t:
+// как и в GNU sort, значение может идти отдельным аргументом или сразу после ключа, при повторе ключа берется последнее
+func getFlagValue(args []string, flag string) (string, bool, error) {
+	value, found := "", false
+	for argIndex := 0; argIndex < len(args); argIndex++ {
+		arg := args[argIndex]
+		switch {
+		case arg == flag:
+			if len(args) <= argIndex+1 {
+				return "", false, fmt.Errorf("not enough arguments given after %s flag", flag)
 			}
+			argIndex++
+			value, found = args[argIndex], true
+		case strings.HasPrefix(arg, flag):
+			value, found = strings.TrimPrefix(arg, flag), true
+		// значение другого ключа не может быть ключом flag
+		case slices.Contains(valueFlags, arg):
+			argIndex++
 		}
-		return splitLines[i][lastCol] < splitLines[j][lastCol]
-	})
-	// сборка полей с конвертацией типа обратно в строки
-	for key, splitLine := range splitLines {
-		stringLine := make([]string, 0)
-		for _, intWord := range splitLine {
-			word := strconv.Itoa(intWord)
-			stringLine = append(stringLine, word)
-		}
-		(*s)[key] = strings.Join(stringLine, " ")
-	}
-
-	// удаление повторяющихся строк на основании поля unique
-	if n.unique {
-		*s = slices.Compact(*s)
-	}
-
-	// разворот слайса на основании поля reverse
-	if n.reverse {
-		slices.Reverse(*s)
 	}
+	return value, found, nil
 }
 
-// checkSlice - реализация метода checkSlice интерфейса iSorter классом numericalSorter
-func (n *numericalSorter) checkSlice(s *[]string) bool {
-	// копирование слайса в новый
-	var sCopy []string
-	copy(sCopy, *s)
-
-	// удаление хвостовых пробелов на основании поля removeTrailingSpaces
-	if n.removeTrailingSpaces {
-		for _, line := range sCopy {
-			strings.TrimRight(line, " \n\r\t")
-		}
-	}
-	// разделение строк на поля c конвертацией строк в числа
-	splitLines := make([][]int, 0)
-	for _, line := range sCopy {
-		splitLine := strings.Fields(line)
-		intSplitLine := make([]int, 0)
-		for _, word := range splitLine {
-			intWord, err := strconv.Atoi(word)
-			if err != nil {
-				log.Fatalln("file contains non-numerical values")
-			}
-			intSplitLine = append(intSplitLine, intWord)
+// getLongFlagValue - функция для получения значения ключа в формате --name=value
+func getLongFlagValue(args []string, name string) (string, bool) {
+	for _, arg := range args {
+		if strings.HasPrefix(arg, name+"=") {
+			return strings.TrimPrefix(arg, name+"="), true
 		}
-		splitLines = append(splitLines, intSplitLine)
 	}
-	// проверка сортировки строк в числовом порядке на основании выбранных полей
-	return sort.SliceIsSorted(splitLines, func(i int, j int) bool {
-		lastCol := 0
-		for col := n.lowerColumn; col >= n.higherColumn; col++ {
-			lastCol = col
-			if splitLines[i][col] != splitLines[j][col] {
-				break
-			}
-		}
-		return splitLines[i][lastCol] < splitLines[j][lastCol]
-	})
+	return "", false
 }
 
-// monthSorter - конкретный класс сортировщика
-type monthSorter struct {
-	sorter
+// bufferSizeSuffixes - множители для суффиксов размера буфера
+var bufferSizeSuffixes = map[byte]int64{
+	'b': 1,
+	'K': 1 << 10,
+	'M': 1 << 20,
+	'G': 1 << 30,
+	'T': 1 << 40,
 }
 
-// sortSlice - реализация метода sortSlice интерфейса iSorter классом monthSorter
-func (m *monthSorter) sortSlice(s *[]string) {
-	// удаление хвостовых пробелов на основании поля removeTrailingSpaces
-	if m.removeTrailingSpaces {
-		for _, line := range *s {
-			strings.TrimRight(line, " \n\r\t")
+// parseBufferSize - функция для разбора размера буфера из ключа -S (например 512K, 100M, 2G)
+func parseBufferSize(value string) (int64, error) {
+	multiplier := int64(1 << 10) // без суффикса размер задан в килобайтах, как в GNU sort
+	if len(value) > 0 {
+		if suffixMultiplier, ok := bufferSizeSuffixes[value[len(value)-1]]; ok {
+			multiplier = suffixMultiplier
+			value = value[:len(value)-1]
 		}
 	}
-	// разделение строк на поля c конвертацией строк в тип time.Month
-	monthLines := make([][]time.Month, 0)
-	for _, line := range *s {
-		splitLine := strings.Fields(line)
-		monthWords := make([]time.Month, 0)
-		for _, word := range splitLine {
-			dateWord, err := time.Parse("January", word)
-			if err != nil {
-				log.Fatalln("file contains non-month values", err)
-			}
-			monthWords = append(monthWords, dateWord.Month())
-		}
-		monthLines = append(monthLines, monthWords)
-	}
-	// сортировка строк в порядке месяцев на основании выбранных полей
-	sort.Slice(monthLines, func(i int, j int) bool {
-		lastCol := 0
-		for col := m.lowerColumn; col >= m.higherColumn; col++ {
-			lastCol = col
-			if monthLines[i][col] != monthLines[j][col] {
-				break
-			}
-		}
-		return monthLines[i][lastCol] < monthLines[j][lastCol]
-	})
-	// сборка полей с конвертацией типа обратно в строки
-	for key, line := range monthLines {
-		for _, monthWord := range line {
-			word := monthWord.String()
-			(*s)[key] = word
-		}
-	}
-
-	// удаление повторяющихся строк на основании поля unique
-	if m.unique {
-		*s = slices.Compact(*s)
+	size, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, err
 	}
-
-	// разворот слайса на основании поля reverse
-	if m.reverse {
-		slices.Reverse(*s)
+	if size <= 0 {
+		return 0, errors.New("buffer size must be positive")
 	}
+	return size * multiplier, nil
 }
 
-// checkSlice - реализация метода checkSlice интерфейса iSorter классом monthSorter
-func (m *monthSorter) checkSlice(s *[]string) bool {
-	// копирование слайса в новый
-	var sCopy []string
-	copy(sCopy, *s)
+// getOptions - функция для получения параметров сортировки из аргументов командной строки
+func getOptions(args []string) (sortx.Options, error) {
+	opts := sortx.Options{
+		Unique: slices.Contains(args, "-u"),
+		Dups:   slices.Contains(args, "--dups"),
+		Stable: slices.Contains(args, "-s"),
+		CSV:    slices.Contains(args, "--csv"),
+	}
 
-	// удаление хвостовых пробелов на основании поля removeTrailingSpaces
-	if m.removeTrailingSpaces {
-		for _, line := range sCopy {
-			strings.TrimRight(line, " \n\r\t")
+	// проверка на ключи -n, -h, -M, -g, -V и -R в порядке приоритета, стратегия сравнения может быть только одна
+	for _, modifier := range "nhMgVR" {
+		if slices.Contains(args, "-"+string(modifier)) {
+			opts.Modifiers += string(modifier)
+			break
 		}
 	}
-	// разделение строк на поля c конвертацией строк в тип time.Month
-	monthLines := make([][]time.Month, 0)
-	for _, line := range sCopy {
-		splitLine := strings.Fields(line)
-		monthWords := make([]time.Month, 0)
-		for _, word := range splitLine {
-			dateWord, err := time.Parse("January", word)
-			if err != nil {
-				log.Fatalln("file contains non-month values", err)
-			}
-			monthWords = append(monthWords, dateWord.Month())
+	// проверка на ключи -r, -b и -f
+	for _, modifier := range "rbf" {
+		if slices.Contains(args, "-"+string(modifier)) {
+			opts.Modifiers += string(modifier)
 		}
-		monthLines = append(monthLines, monthWords)
 	}
-	// проверка сортировки строк в порядке месяцев на основании выбранных полей
-	return sort.SliceIsSorted(monthLines, func(i int, j int) bool {
-		lastCol := 0
-		for col := m.lowerColumn; col >= m.higherColumn; col++ {
-			lastCol = col
-			if monthLines[i][col] != monthLines[j][col] {
-				break
-			}
-		}
-		return monthLines[i][lastCol] < monthLines[j][lastCol]
-	})
-}
-
-// humanNumericalSorter - конкретный класс сортировщика
-type humanNumericalSorter struct {
-	sorter
-}
 
-// sortSlice - реализация метода sortSlice интерфейса iSorter классом humanNumericalSorter
-func (h *humanNumericalSorter) sortSlice(s *[]string) {
-	// удаление хвостовых пробелов на основании поля removeTrailingSpaces
-	if h.removeTrailingSpaces {
-		for _, line := range *s {
-			strings.TrimRight(line, " \n\r\t")
-		}
-	}
-	// разделение строк на поля
-	splitLines := make([][]string, 0)
-	for _, line := range *s {
-		splitLines = append(splitLines, strings.Split(line, " "))
-	}
-	re, err := regexp.Compile("[0-9]+") // создание regexp для поиска лидирующих чисел
+	// получение ключей сортировки из всех ключей -k
+	specs, err := getKeySpecs(args)
 	if err != nil {
-		log.Fatalln("incorrect regexp", err)
+		return opts, err
 	}
-	// сортировка строк в числовом порядке относительно лидирующих чисел
-	sort.Slice(splitLines, func(i int, j int) bool {
-		lastCol := 0
-		for col := h.lowerColumn; col >= h.higherColumn; col++ {
-			lastCol = col
-			// сравнение лидирующих чисел через regexp
-			if string(re.Find([]byte(splitLines[i][col]))) != string(re.Find([]byte(splitLines[j][col]))) {
-				break
-			}
-		}
-		// конвертация лидирующих чисел в int
-		val1, err := strconv.Atoi(string(re.Find([]byte(splitLines[i][lastCol]))))
-		if err != nil {
-			log.Fatalln(err)
-		}
-		val2, err := strconv.Atoi(string(re.Find([]byte(splitLines[j][lastCol]))))
+	for _, spec := range specs {
+		key, err := sortx.ParseKey(spec)
 		if err != nil {
-			log.Fatalln(err)
+			return opts, fmt.Errorf("incorrect key given: %w", err)
 		}
-		// возврат результата сравнения
-		return val1 < val2
-	})
-
-	for key, splitLine := range splitLines {
-		(*s)[key] = strings.Join(splitLine, " ")
+		opts.Keys = append(opts.Keys, key)
 	}
 
-	if h.unique {
-		*s = slices.Compact(*s)
-	}
-	if h.reverse {
-		slices.Reverse(*s)
+	// получение разделителя полей из ключа -t, например -t $'\t' вместе с --csv для TSV
+	if opts.Separator, _, err = getFlagValue(args, "-t"); err != nil {
+		return opts, err
 	}
-}
 
-// checkSlice - реализация метода checkSlice интерфейса iSorter классом humanNumericalSorter
-func (h *humanNumericalSorter) checkSlice(s *[]string) bool {
-	// копирование слайса в новый
-	var sCopy []string
-	copy(sCopy, *s)
-
-	// удаление хвостовых пробелов на основании поля removeTrailingSpaces
-	if h.removeTrailingSpaces {
-		for _, line := range sCopy {
-			strings.TrimRight(line, " \n\r\t")
-		}
-	}
-	// разделение строк на поля
-	splitLines := make([][]string, 0)
-	for _, line := range sCopy {
-		splitLines = append(splitLines, strings.Fields(line))
-	}
-	re, err := regexp.Compile("[0-9]+") // создание regexp для поиска лидирующих чисел
+	// получение размера буфера для сортировки в памяти из ключа -S
+	value, ok, err := getFlagValue(args, "-S")
 	if err != nil {
-		log.Fatalln("incorrect regexp", err)
+		return opts, err
 	}
-	// проверка сортировки строк в числовом порядке относительно лидирующих чисел
-	return sort.SliceIsSorted(splitLines, func(i int, j int) bool {
-		lastCol := 0
-		for col := h.lowerColumn; col >= h.higherColumn; col++ {
-			lastCol = col
-			// сравнение лидирующих чисел через regexp
-			if string(re.Find([]byte(splitLines[i][col]))) != string(re.Find([]byte(splitLines[j][col]))) {
-				break
-			}
-		}
-		// конвертация лидирующих чисел в int
-		val1, err := strconv.Atoi(string(re.Find([]byte(splitLines[i][lastCol]))))
-		if err != nil {
-			log.Fatalln(err)
-		}
-		val2, err := strconv.Atoi(string(re.Find([]byte(splitLines[j][lastCol]))))
+	if ok {
+		size, err := parseBufferSize(value)
 		if err != nil {
-			log.Fatalln(err)
+			return opts, fmt.Errorf("incorrect buffer size given: %w", err)
 		}
-		// возврат результата сравнения
-		return val1 < val2
-	})
-}
-
-// sortContext - класс контекст для сортировщиков
-type sortContext struct {
-	sort iSorter
-}
-
-// sortLines - метод для вызова внутреннего метода сортировщика sortLines
-func (c *sortContext) sortLines(s *[]string) {
-	c.sort.sortSlice(s)
-}
-
-// checkSortedLines - метод для вызова внутреннего метода сортировщика checkSortedLines
-func (c *sortContext) checkSortedLines(s *[]string) bool {
-	return c.sort.checkSlice(s)
-}
-
-// getColumns - функция для получения номеров столбцов из командной строки
-func getColumns(argIndex, guaranteedColumns int) (lowerColumn, higherColumn int) {
-	// получение аргумента, следующего за ключом
-	cols := os.Args[argIndex+1]
-	// проверка формата аргумента
-	if strings.Count(cols, ",") != 1 {
-		log.Fatalln("incorrect column indexes given")
-	}
-	// получение номеров столбцов
-	splitCols := strings.Split(cols, ",")
-	lowerCol, err := strconv.Atoi(splitCols[0])
-	if err != nil {
-		log.Fatalln("non-numerical colon index given:", err)
-	}
-	lowerCol-- // уменьшение номера первого столбца на 1 для получения корректного индекса
-	higherCol, err := strconv.Atoi(splitCols[0])
-	if err != nil {
-		log.Fatalln("non-numerical colon index given:", err)
+		opts.BufferSize = size
 	}
-	// сравнение номеров столбцов с количеством полных столбцов
-	if higherCol > guaranteedColumns || lowerCol > guaranteedColumns {
-		log.Fatalln("given column indexes exceed number of complete columns")
-	}
-	// сравнение номеров начального и конечного столбца
-	if lowerCol >= higherCol {
-		log.Fatalln("incorrect column indexes given")
-	}
-	return lowerCol, higherCol
-}
-
-func main() {
-	filename := os.Args[1] // получение пути к файлу для обработки
 
-	// открытие выбранного файла для чтения и записи
-	file, err := os.OpenFile(filename, os.O_RDWR, os.FileMode(0755))
-	if err != nil {
-		log.Fatalln("file opening error, file:", filename, err)
+	// получение директории для временных файлов из ключа -T
+	if opts.TempDir, _, err = getFlagValue(args, "-T"); err != nil {
+		return opts, err
 	}
 
-	// закрытие файла в defer, чтобы избежать утечки
-	defer func(file *os.File) {
-		err := file.Close()
-		if err != nil {
+	// получение языка для сравнения текста из ключа --locale=LANG
+	opts.Locale, _ = getLongFlagValue(args, "--locale")
 
+	// получение seed для случайной сортировки из ключа --seed=N
+	if value, ok := getLongFlagValue(args, "--seed"); ok {
+		seed, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return opts, fmt.Errorf("incorrect random seed given: %w", err)
 		}
-	}(file)
-
-	scanner := bufio.NewScanner(file) // создание сканера для чтения файла
-
-	scanner.Split(bufio.ScanLines) // установка функции разделения для чтения по строкам
-
-	lines := make([]string, 0) // создание слайса строк
-
-	// чтение строк из файла
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
+		opts.Seed = seed
 	}
 
-	if err = scanner.Err(); err != nil {
-		log.Fatalln(err)
-	}
-
-	// проверка на ключ -r
-	isReverse := false
-
-	if slices.Contains(os.Args[2:], "-r") {
-		isReverse = true
-	}
-
-	// проверка на ключ -u
-	isUnique := false
-
-	if slices.Contains(os.Args[2:], "-u") {
-		isUnique = true
+	// проверка на ключ --parallel=N
+	if value, ok := getLongFlagValue(args, "--parallel"); ok {
+		workers, err := strconv.Atoi(value)
+		if err != nil || workers < 1 {
+			return opts, fmt.Errorf("incorrect number of parallel workers given: %s", value)
+		}
+		opts.Parallel = workers
 	}
+	return opts, nil
+}
 
-	// проверка на ключ -b
-	ignoreTrailingSpaces := false
-
-	if slices.Contains(os.Args[2:], "-b") {
-		ignoreTrailingSpaces = true
+func main() {
+	inputNames := getInputFiles(os.Args[1:])
+	opts, err := getOptions(os.Args[1:])
+	if err != nil {
+		fatal(err)
 	}
 
-	// получение количества полных столбцов
-	guaranteedColumns := len(strings.Fields(lines[0]))
-
-	for _, line := range lines {
-		colsCount := len(strings.Fields(line))
-		if colsCount < guaranteedColumns {
-			guaranteedColumns = colsCount
+	// проверка на ключи -c и -C
+	if slices.Contains(os.Args[1:], "-c") || slices.Contains(os.Args[1:], "-C") {
+		if len(inputNames) > 1 {
+			fatal("only one input file is allowed with -c and -C flags")
 		}
-	}
-
-	var sliceSorter *sortContext // объявление контекста сортировщика
-
-	switch {
-	// проверка на ключ -n
-	case slices.Contains(os.Args[2:], "-n"):
-		// проверка на ключ -k
-		if slices.Contains(os.Args, "-k") {
-			argIndex := slices.Index(os.Args, "-k")
-			if len(os.Args) > argIndex+1 {
-				lowerCol, higherCol := getColumns(argIndex, guaranteedColumns) // получение номеров столбцов
-
-				// создание объекта сортировщика в числовом порядке с указанными столбцами
-				sliceSorter = &sortContext{
-					sort: &numericalSorter{
-						sorter: sorter{
-							unique:               isUnique,
-							reverse:              isReverse,
-							removeTrailingSpaces: ignoreTrailingSpaces,
-							higherColumn:         higherCol,
-							lowerColumn:          lowerCol,
-						},
-					},
-				}
-
-			} else {
-				log.Fatalln("not enough arguments given after -k flag")
-			}
-		} else {
-			// создание объекта сортировщика в числовом порядке для всех столбцов
-			sliceSorter = &sortContext{
-				sort: &numericalSorter{
-					sorter: sorter{
-						unique:               isUnique,
-						reverse:              isReverse,
-						removeTrailingSpaces: ignoreTrailingSpaces,
-						higherColumn:         guaranteedColumns,
-						lowerColumn:          0,
-					},
-				},
+		input := os.Stdin
+		if inputNames[0] != "-" {
+			file, err := os.Open(inputNames[0])
+			if err != nil {
+				fatal("file opening error, file:", inputNames[0], err)
 			}
+			defer func(file *os.File) {
+				_ = file.Close()
+			}(file)
+			input = file
 		}
-	// проверка на ключ -h
-	case slices.Contains(os.Args[2:], "-h"):
-		// проверка на ключ -k
-		if slices.Contains(os.Args, "-k") {
-			argIndex := slices.Index(os.Args, "-k")
-			if len(os.Args) > argIndex+1 {
-				lowerCol, higherCol := getColumns(argIndex, guaranteedColumns) // получение номеров столбцов
-
-				// создание объекта сортировщика в числовом порядке на основании лидирующих чисел с указанными столбцами
-				sliceSorter = &sortContext{
-					sort: &humanNumericalSorter{
-						sorter: sorter{
-							unique:               isUnique,
-							reverse:              isReverse,
-							removeTrailingSpaces: ignoreTrailingSpaces,
-							higherColumn:         higherCol,
-							lowerColumn:          lowerCol,
-						},
-					},
-				}
-
-			} else {
-				log.Fatalln("not enough arguments given after -k flag")
-			}
-		} else {
-			// создание объекта сортировщика в числовом порядке на основании лидирующих чисел для всех столбцов
-			sliceSorter = &sortContext{
-				sort: &humanNumericalSorter{
-					sorter: sorter{
-						unique:               isUnique,
-						reverse:              isReverse,
-						removeTrailingSpaces: ignoreTrailingSpaces,
-						higherColumn:         guaranteedColumns,
-						lowerColumn:          0,
-					},
-				},
-			}
+		lineNum, line, err := sortx.Check(input, opts)
+		if err != nil {
+			fatal(err)
 		}
-	// проверка на ключ -M
-	case slices.Contains(os.Args[2:], "-M"):
-		// проверка на ключ -k
-		if slices.Contains(os.Args, "-k") {
-			argIndex := slices.Index(os.Args, "-k")
-			if len(os.Args) > argIndex+1 {
-				lowerCol, higherCol := getColumns(argIndex, guaranteedColumns) // получение номеров столбцов
-
-				// создание объекта сортировщика в порядке месяцев с указанными столбцами
-				sliceSorter = &sortContext{
-					sort: &monthSorter{
-						sorter: sorter{
-							unique:               isUnique,
-							reverse:              isReverse,
-							removeTrailingSpaces: ignoreTrailingSpaces,
-							higherColumn:         higherCol,
-							lowerColumn:          lowerCol,
-						},
-					},
-				}
-
-			} else {
-				log.Fatalln("not enough arguments given after -k flag")
-			}
-		} else {
-			// создание объекта сортировщика в порядке месяцев для всех столбцов
-			sliceSorter = &sortContext{
-				sort: &monthSorter{
-					sorter: sorter{
-						unique:               isUnique,
-						reverse:              isReverse,
-						removeTrailingSpaces: ignoreTrailingSpaces,
-						higherColumn:         guaranteedColumns,
-						lowerColumn:          0,
-					},
-				},
-			}
+		if lineNum == 0 {
+			return
 		}
-	default:
-		// проверка на ключ -k
-		if slices.Contains(os.Args, "-k") {
-			argIndex := slices.Index(os.Args, "-k")
-			if len(os.Args) > argIndex+1 {
-				lowerCol, higherCol := getColumns(argIndex, guaranteedColumns) // получение номеров столбцов
-
-				// создание объекта сортировщика в алфавитном порядке с указанными столбцами
-				sliceSorter = &sortContext{
-					sort: &alphabeticalSorter{
-						sorter: sorter{
-							unique:               isUnique,
-							reverse:              isReverse,
-							removeTrailingSpaces: ignoreTrailingSpaces,
-							higherColumn:         higherCol,
-							lowerColumn:          lowerCol,
-						},
-					},
-				}
-
-			} else {
-				log.Fatalln("not enough arguments given after -k flag")
-			}
-		} else {
-			// создание объекта сортировщика в алфавитном порядке для всех столбцов
-			sliceSorter = &sortContext{
-				sort: &alphabeticalSorter{
-					sorter: sorter{
-						unique:               isUnique,
-						reverse:              isReverse,
-						removeTrailingSpaces: ignoreTrailingSpaces,
-						higherColumn:         guaranteedColumns,
-						lowerColumn:          0,
-					},
-				},
-			}
+		// при -c выводится первая строка, нарушающая порядок, при -C только код возврата
+		if slices.Contains(os.Args[1:], "-c") {
+			fmt.Fprintf(os.Stderr, "%s:%d: disorder: %s\n", inputNames[0], lineNum, line)
 		}
+		os.Exit(exitDisorder)
 	}
 
-	// проверка на ключ -c
-	if slices.Contains(os.Args[2:], "-c") {
-		fmt.Println(sliceSorter.checkSortedLines(&lines)) // вызов метода для проверки сортировки
-	} else {
-		sliceSorter.sortLines(&lines) // вызов метода для сортировки
-	}
-
-	// очистка файла
-	err = file.Truncate(0)
+	// получение пути к файлу результата из ключа -o, по умолчанию результат пишется в stdout
+	outputName, _, err := getFlagValue(os.Args[1:], "-o")
 	if err != nil {
-		log.Fatalln(err)
+		fatal(err)
 	}
 
-	// установка оффсета файла на 0
-	_, err = file.Seek(0, 0)
-	if err != nil {
-		log.Fatalln(err)
+	// проверка на ключ -m
+	if slices.Contains(os.Args[1:], "-m") {
+		// слияние уже отсортированных входных файлов без повторной сортировки
+		err = sortx.MergeFiles(inputNames, outputName, opts)
+	} else {
+		// сортировка строк из всех входных файлов
+		err = sortx.SortFiles(inputNames, outputName, opts)
 	}
-
-	// запись строк в файл
-	for _, line := range lines {
-		_, err := file.WriteString(fmt.Sprintf("%s", line) + "\n")
-		if err != nil {
-			return
-		}
+	if err != nil {
+		fatal(err)
 	}
 }
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+type bufferSizeTest struct {
+	value string
+	size  int64
+	err   bool
+}
+
+var bufferSizeTests = []bufferSizeTest{
+	{"10", 10 << 10, false},
+	{"512b", 512, false},
+	{"100M", 100 << 20, false},
+	{"2G", 2 << 30, false},
+	{"0", 0, true},
+	{"x", 0, true},
+}
+
+func TestParseBufferSize(t *testing.T) {
+	for _, test := range bufferSizeTests {
+		size, err := parseBufferSize(test.value)
+		if (err != nil) != test.err || size != test.size {
+			t.Errorf("Output %v, %v was not equal to expected %v for %q", size, err, test.size, test.value)
+		}
+	}
+}
+
+type inputFilesTest struct {
+	args  []string
+	files []string
+}
+
+var inputFilesTests = []inputFilesTest{
+	{[]string{"-n", "a.txt", "b.txt"}, []string{"a.txt", "b.txt"}},
+	{[]string{"-k", "2,2n", "-o", "out.txt", "a.txt"}, []string{"a.txt"}},
+	{[]string{"a.txt", "-", "b.txt"}, []string{"a.txt", "-", "b.txt"}},
+	{[]string{"-r"}, []string{"-"}},
+	{[]string{"-t:", "-k3,3n", "-o/tmp/out.txt", "/etc/passwd"}, []string{"/etc/passwd"}},
+	{[]string{"-t", ":", "-S512K", "-T/tmp", "a.txt"}, []string{"a.txt"}},
+}
+
+func TestGetInputFiles(t *testing.T) {
+	for _, test := range inputFilesTests {
+		if files := getInputFiles(test.args); !slices.Equal(files, test.files) {
+			t.Errorf("Output %q was not equal to expected %q for %q", files, test.files, test.args)
+		}
+	}
+}
+
+type keySpecsTest struct {
+	args  []string
+	specs []string
+	err   bool
+}
+
+var keySpecsTests = []keySpecsTest{
+	{[]string{"-k", "2,2n", "a.txt"}, []string{"2,2n"}, false},
+	{[]string{"-k2n", "-k", "1,1", "a.txt"}, []string{"2n", "1,1"}, false},
+	{[]string{"-o", "-k1", "-k3.2b"}, []string{"3.2b"}, false},
+	{[]string{"-n", "a.txt"}, []string{}, false},
+	{[]string{"a.txt", "-k"}, nil, true},
+}
+
+func TestGetKeySpecs(t *testing.T) {
+	for _, test := range keySpecsTests {
+		specs, err := getKeySpecs(test.args)
+		if (err != nil) != test.err || !slices.Equal(specs, test.specs) {
+			t.Errorf("Output %q, %v was not equal to expected %q for %q", specs, err, test.specs, test.args)
+		}
+	}
+}
+
+type optionsTest struct {
+	args       []string
+	separator  string
+	bufferSize int64
+	tempDir    string
+	err        bool
+}
+
+var optionsTests = []optionsTest{
+	{[]string{"-t:", "-k3,3n", "/etc/passwd"}, ":", 0, "", false},
+	{[]string{"-t", ":", "-S", "2M", "-T", "/tmp", "a.txt"}, ":", 2 << 20, "/tmp", false},
+	{[]string{"-t:", "-S512K", "-T/tmp", "a.txt"}, ":", 512 << 10, "/tmp", false},
+	{[]string{"-t", ",", "-t;"}, ";", 0, "", false},
+	{[]string{"-o", "-t", "a.txt"}, "", 0, "", false},
+	{[]string{"-S0K"}, "", 0, "", true},
+	{[]string{"a.txt", "-t"}, "", 0, "", true},
+}
+
+func TestGetOptions(t *testing.T) {
+	for _, test := range optionsTests {
+		opts, err := getOptions(test.args)
+		if (err != nil) != test.err {
+			t.Errorf("Output %v was not equal to expected %v for %q", err, test.err, test.args)
+			continue
+		}
+		if !test.err && (opts.Separator != test.separator || opts.BufferSize != test.bufferSize || opts.TempDir != test.tempDir) {
+			t.Errorf("Output %q, %v, %q was not equal to expected %q, %v, %q for %q",
+				opts.Separator, opts.BufferSize, opts.TempDir, test.separator, test.bufferSize, test.tempDir, test.args)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+// acNode - узел автомата Ахо-Корасик
+type acNode struct {
+	next          map[byte]int32 // переходы по бору
+	fail          int32          // суффиксная ссылка на узел с самым длинным собственным суффиксом
+	dict          int32          // ближайший по суффиксным ссылкам узел, в котором заканчивается шаблон, -1 - нет такого узла
+	patternLength int32          // длина шаблона, заканчивающегося в узле, 0 - шаблон не заканчивается
+}
+
+// ahoCorasick - класс для одновременного поиска множества строк за один проход по тексту
+type ahoCorasick struct {
+	nodes    []acNode
+	hasEmpty bool // среди шаблонов есть пустая строка, которая совпадает с любым текстом
+}
+
+// newAhoCorasick - функция для построения автомата по набору шаблонов
+func newAhoCorasick(patterns []string) *ahoCorasick {
+	ac := &ahoCorasick{nodes: []acNode{{next: map[byte]int32{}, dict: -1}}}
+
+	// построение бора из всех шаблонов
+	for _, pattern := range patterns {
+		if pattern == "" {
+			ac.hasEmpty = true
+			continue
+		}
+		node := int32(0)
+		for i := 0; i < len(pattern); i++ {
+			child, ok := ac.nodes[node].next[pattern[i]]
+			if !ok {
+				child = int32(len(ac.nodes))
+				ac.nodes = append(ac.nodes, acNode{next: map[byte]int32{}, dict: -1})
+				ac.nodes[node].next[pattern[i]] = child
+			}
+			node = child
+		}
+		ac.nodes[node].patternLength = int32(len(pattern))
+	}
+
+	// вычисление суффиксных ссылок обходом бора в ширину
+	queue := make([]int32, 0, len(ac.nodes))
+	for _, child := range ac.nodes[0].next {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for symbol, child := range ac.nodes[node].next {
+			fail := ac.nodes[node].fail
+			for fail != 0 && !ac.hasEdge(fail, symbol) {
+				fail = ac.nodes[fail].fail
+			}
+			if next, ok := ac.nodes[fail].next[symbol]; ok && next != child {
+				fail = next
+			}
+			ac.nodes[child].fail = fail
+			// словарная ссылка указывает на ближайший суффикс, который сам является шаблоном
+			if ac.nodes[fail].patternLength > 0 {
+				ac.nodes[child].dict = fail
+			} else {
+				ac.nodes[child].dict = ac.nodes[fail].dict
+			}
+			queue = append(queue, child)
+		}
+	}
+	return ac
+}
+
+// hasEdge - метод для проверки наличия перехода из узла по символу
+func (ac *ahoCorasick) hasEdge(node int32, symbol byte) bool {
+	_, ok := ac.nodes[node].next[symbol]
+	return ok
+}
+
+// step - метод для перехода автомата из узла по символу с учетом суффиксных ссылок
+func (ac *ahoCorasick) step(node int32, symbol byte) int32 {
+	for {
+		if next, ok := ac.nodes[node].next[symbol]; ok {
+			return next
+		}
+		if node == 0 {
+			return 0
+		}
+		node = ac.nodes[node].fail
+	}
+}
+
+// contains - метод для проверки, встречается ли в тексте хотя бы один шаблон
+func (ac *ahoCorasick) contains(text string) bool {
+	if ac.hasEmpty {
+		return true
+	}
+	node := int32(0)
+	for i := 0; i < len(text); i++ {
+		node = ac.step(node, text[i])
+		if ac.nodes[node].patternLength > 0 || ac.nodes[node].dict != -1 {
+			return true
+		}
+	}
+	return false
+}
+
+// findAll - метод для поиска непересекающихся вхождений шаблонов в тексте
+// как и в grep, из вхождений с общим началом выбирается самое длинное, а поиск продолжается после него
+func (ac *ahoCorasick) findAll(text string) [][]int {
+	// longest[start] - длина самого длинного шаблона, начинающегося в позиции start
+	longest := make([]int32, len(text)+1)
+	node := int32(0)
+	for i := 0; i < len(text); i++ {
+		node = ac.step(node, text[i])
+		// перебор всех шаблонов, заканчивающихся в позиции i
+		for match := node; match != -1; match = ac.nodes[match].dict {
+			length := ac.nodes[match].patternLength
+			if length == 0 {
+				continue
+			}
+			start := i + 1 - int(length)
+			longest[start] = max(longest[start], length)
+		}
+	}
+
+	matches := make([][]int, 0)
+	for start := 0; start <= len(text); start++ {
+		switch {
+		case longest[start] > 0:
+			end := start + int(longest[start])
+			matches = append(matches, []int{start, end})
+			start = end - 1
+		// пустой шаблон совпадает в начале текста, если там нет других совпадений
+		case ac.hasEmpty && start == 0:
+			matches = append(matches, []int{0, 0})
+		}
+	}
+	return matches
+}
+
+// longestPrefix - метод для поиска самого длинного шаблона, которым начинается текст
+// возвращает длину шаблона или -1, если текст не начинается ни с одного шаблона
+func (ac *ahoCorasick) longestPrefix(text string) int {
+	longest := -1
+	if ac.hasEmpty {
+		longest = 0
+	}
+	// переходы только по бору без суффиксных ссылок проходят шаблоны, начинающиеся в начале текста
+	node := int32(0)
+	for i := 0; i < len(text); i++ {
+		next, ok := ac.nodes[node].next[text[i]]
+		if !ok {
+			break
+		}
+		node = next
+		if ac.nodes[node].patternLength > 0 {
+			longest = i + 1
+		}
+	}
+	return longest
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"strings"
+)
+
+// binaryMode - политика обработки двоичных файлов
+type binaryMode int
+
+const (
+	binaryMatches      binaryMode = iota // по умолчанию, вместо строк выводится сообщение "Binary file X matches"
+	binaryText                           // флаг -a, двоичные файлы обрабатываются как текст
+	binaryWithoutMatch                   // двоичные файлы считаются не содержащими совпадений
+)
+
+// binaryPeekSize - количество первых байтов файла, в которых ищется NUL до начала поиска
+const binaryPeekSize = 32 << 10
+
+// parseBinaryMode - функция для разбора политики из ключа --binary-files=TYPE
+func parseBinaryMode(mode string) (binaryMode, error) {
+	switch mode {
+	case "binary":
+		return binaryMatches, nil
+	case "text":
+		return binaryText, nil
+	case "without-match":
+		return binaryWithoutMatch, nil
+	}
+	return binaryMatches, errors.New("unknown binary files type " + mode)
+}
+
+// binaryDetector - класс для определения двоичных данных по байту NUL во время потокового чтения
+type binaryDetector struct {
+	binary bool
+	offset int // смещение первого байта NUL от начала файла
+}
+
+// checkHeader - метод для поиска NUL в начале данных без их чтения
+func (d *binaryDetector) checkHeader(reader *bufio.Reader) {
+	// данные короче заголовка проверяются целиком, поэтому ошибка чтения не важна
+	header, _ := reader.Peek(binaryPeekSize)
+	if index := bytes.IndexByte(header, 0); index != -1 {
+		d.binary, d.offset = true, index
+	}
+}
+
+// checkLine - метод для поиска NUL в очередной строке, если NUL не встретился раньше
+func (d *binaryDetector) checkLine(line inputLine) {
+	if d.binary {
+		return
+	}
+	if index := strings.IndexByte(line.text, 0); index != -1 {
+		d.binary, d.offset = true, line.offset+index
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+// ANSI-последовательности для подсветки, как в GNU grep по умолчанию
+const (
+	colorMatch     = "\x1b[01;31m\x1b[K"
+	colorFilename  = "\x1b[35m\x1b[K"
+	colorNumber    = "\x1b[32m\x1b[K"
+	colorSeparator = "\x1b[36m\x1b[K"
+	colorReset     = "\x1b[m\x1b[K"
+)
+
+// isTerminal - функция для проверки, подключен ли файл к терминалу
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// parseColorMode - функция для разбора режима подсветки из ключа --color=WHEN
+// при auto подсветка включается, только если вывод идет в терминал
+func parseColorMode(mode string, output *os.File) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto":
+		return isTerminal(output) && os.Getenv("TERM") != "dumb", nil
+	}
+	return false, errors.New("unknown color mode " + mode)
+}
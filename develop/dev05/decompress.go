@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// decompressor - формат сжатия, определяемый по первым байтам данных
+type decompressor struct {
+	magic     []byte
+	newReader func(io.Reader) (io.ReadCloser, error)
+}
+
+// decompressors - поддерживаемые форматы сжатия
+var decompressors = []decompressor{
+	// gzip
+	{magic: []byte{0x1f, 0x8b}, newReader: func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	}},
+	// bzip2
+	{magic: []byte("BZh"), newReader: func(r io.Reader) (io.ReadCloser, error) {
+		return io.NopCloser(bzip2.NewReader(r)), nil
+	}},
+	// zstd, распаковка в одной горутине, так как файлы и так читаются параллельно
+	{magic: []byte{0x28, 0xb5, 0x2f, 0xfd}, newReader: func(r io.Reader) (io.ReadCloser, error) {
+		decoder, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	}},
+}
+
+// maxMagicLength - количество первых байтов, по которым определяется формат сжатия
+const maxMagicLength = 4
+
+// decompress - функция для распаковки данных на лету, формат определяется по первым байтам
+// данные без известного формата сжатия возвращаются без изменений
+func decompress(r io.Reader) (io.ReadCloser, error) {
+	reader := bufio.NewReader(r)
+	// данные короче заголовка не могут быть сжаты, поэтому ошибка чтения заголовка не важна
+	header, _ := reader.Peek(maxMagicLength)
+	for _, format := range decompressors {
+		if bytes.HasPrefix(header, format.magic) {
+			return format.newReader(reader)
+		}
+	}
+	return io.NopCloser(reader), nil
+}
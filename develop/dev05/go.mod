@@ -0,0 +1,5 @@
+module example.com/dev05
+
+go 1.22
+
+require github.com/klauspost/compress v1.18.0
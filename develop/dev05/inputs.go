@@ -0,0 +1,97 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// stdinName - имя, под которым выводятся строки из stdin
+const stdinName = "(standard input)"
+
+// inputWalker - класс для обхода входных файлов, в том числе рекурсивного обхода директорий
+type inputWalker struct {
+	recursive   bool
+	includes    []string // шаблоны имен файлов, которые нужно искать, пустой слайс - все файлы
+	excludes    []string // шаблоны имен файлов, которые нужно пропустить
+	excludeDirs []string // шаблоны имен директорий, которые нужно пропустить
+}
+
+// matchAny - функция для проверки имени файла на совпадение с одним из шаблонов
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// selectFile - метод для проверки файла по шаблонам --include и --exclude
+func (w *inputWalker) selectFile(path string) bool {
+	name := filepath.Base(path)
+	if matchAny(w.excludes, name) {
+		return false
+	}
+	return len(w.includes) == 0 || matchAny(w.includes, name)
+}
+
+// walk - метод для вызова visit для каждого входного файла в порядке аргументов
+// "-" означает stdin, директории обходятся только при recursive
+func (w *inputWalker) walk(paths []string, visit func(path string) error) error {
+	for _, path := range paths {
+		if path == "-" {
+			if err := visit(path); err != nil {
+				return err
+			}
+			continue
+		}
+		info, err := os.Stat(path)
+		// ошибка открытия файла обрабатывается при его чтении
+		if err != nil || !info.IsDir() {
+			if err != nil || w.selectFile(path) {
+				if err = visit(path); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if !w.recursive {
+			if err = visit(path); err != nil {
+				return err
+			}
+			continue
+		}
+		// рекурсивный обход директории в лексикографическом порядке
+		err = filepath.WalkDir(path, func(filePath string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return visit(filePath)
+			}
+			if entry.IsDir() {
+				if filePath != path && matchAny(w.excludeDirs, entry.Name()) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			// пропуск символических ссылок и других специальных файлов
+			if !entry.Type().IsRegular() || !w.selectFile(filePath) {
+				return nil
+			}
+			return visit(filePath)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasDirectory - функция для проверки, есть ли среди путей директория
+func hasDirectory(paths []string) bool {
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+	"unicode/utf8"
+)
+
+// jsonEvent - событие поиска в формате JSON, как у ripgrep: begin, match, context, end или summary
+type jsonEvent struct {
+	Type string `json:"type"`
+	Data any    `json:"data"`
+}
+
+// jsonText - строка в формате JSON, строки не в UTF-8 передаются в base64 в поле bytes
+type jsonText struct {
+	Text  *string `json:"text,omitempty"`
+	Bytes *string `json:"bytes,omitempty"`
+}
+
+// newJSONText - функция для создания строки в формате JSON
+func newJSONText(text string) jsonText {
+	if utf8.ValidString(text) {
+		return jsonText{Text: &text}
+	}
+	encoded := base64.StdEncoding.EncodeToString([]byte(text))
+	return jsonText{Bytes: &encoded}
+}
+
+// jsonBegin - данные события begin, начала вывода результатов по файлу
+type jsonBegin struct {
+	Path jsonText `json:"path"`
+}
+
+// jsonSpan - совпадение или группа захвата с позициями в байтах от начала строки
+type jsonSpan struct {
+	Match jsonText `json:"match"`
+	Start int      `json:"start"`
+	End   int      `json:"end"`
+}
+
+// jsonSubmatch - совпадение в строке вместе с группами захвата регулярного выражения
+// группа, не участвовавшая в совпадении, передается как null
+// при --replace в replacement передается результат подстановки в шаблон, как у ripgrep
+type jsonSubmatch struct {
+	jsonSpan
+	Replacement *jsonText   `json:"replacement,omitempty"`
+	Groups      []*jsonSpan `json:"groups,omitempty"`
+}
+
+// jsonLine - данные событий match и context
+type jsonLine struct {
+	Path           jsonText       `json:"path"`
+	Lines          jsonText       `json:"lines"`
+	LineNumber     int            `json:"line_number"`
+	AbsoluteOffset int            `json:"absolute_offset"`
+	Submatches     []jsonSubmatch `json:"submatches"`
+}
+
+// jsonDuration - продолжительность поиска
+type jsonDuration struct {
+	Secs  int64  `json:"secs"`
+	Nanos int    `json:"nanos"`
+	Human string `json:"human"`
+}
+
+// newJSONDuration - функция для создания продолжительности в формате JSON
+func newJSONDuration(duration time.Duration) jsonDuration {
+	return jsonDuration{
+		Secs:  int64(duration / time.Second),
+		Nanos: int(duration % time.Second),
+		Human: fmt.Sprintf("%.6fs", duration.Seconds()),
+	}
+}
+
+// jsonStats - статистика поиска в формате JSON
+type jsonStats struct {
+	Elapsed           jsonDuration `json:"elapsed"`
+	Searches          int          `json:"searches"`
+	SearchesWithMatch int          `json:"searches_with_match"`
+	BytesSearched     int          `json:"bytes_searched"`
+	BytesPrinted      int          `json:"bytes_printed"`
+	MatchedLines      int          `json:"matched_lines"`
+	Matches           int          `json:"matches"`
+}
+
+// newJSONStats - функция для создания статистики в формате JSON
+func newJSONStats(stats searchStats) jsonStats {
+	return jsonStats{
+		Elapsed:           newJSONDuration(stats.elapsed),
+		Searches:          stats.searches,
+		SearchesWithMatch: stats.searchesWithMatch,
+		BytesSearched:     stats.bytesSearched,
+		BytesPrinted:      stats.bytesPrinted,
+		MatchedLines:      stats.matchedLines,
+		Matches:           stats.matches,
+	}
+}
+
+// jsonEnd - данные события end, окончания вывода результатов по файлу
+type jsonEnd struct {
+	Path         jsonText  `json:"path"`
+	BinaryOffset *int      `json:"binary_offset"`
+	Stats        jsonStats `json:"stats"`
+}
+
+// jsonSummary - данные итогового события summary
+type jsonSummary struct {
+	ElapsedTotal jsonDuration `json:"elapsed_total"`
+	Stats        jsonStats    `json:"stats"`
+}
+
+// jsonPrinter - класс для вывода результатов поиска в одном файле в виде событий JSON, по одному в строке
+// как и в ripgrep, события begin и end выводятся только для файлов с выбранными строками
+type jsonPrinter struct {
+	*bufio.Writer
+	output       *countingWriter
+	searcher     *searcher
+	name         string
+	begun        bool // было ли выведено событие begin
+	binaryOffset *int // смещение первого байта NUL в двоичном файле
+}
+
+// writeEvent - функция для вывода события в отдельной строке
+func writeEvent(w io.Writer, eventType string, data any) {
+	_ = json.NewEncoder(w).Encode(jsonEvent{Type: eventType, Data: data})
+}
+
+// printLine - реализация метода printLine интерфейса iPrinter классом jsonPrinter
+func (p *jsonPrinter) printLine(line inputLine, selected bool, matches [][]int) {
+	p.begin()
+
+	submatches := make([]jsonSubmatch, 0, len(matches))
+	for _, match := range matches {
+		submatch := jsonSubmatch{jsonSpan: newJSONSpan(line.text, match[0], match[1])}
+		if opts := p.searcher.opts; opts.replace {
+			replacement := newJSONText(p.searcher.matcher.expand(opts.template, line.text, match))
+			submatch.Replacement = &replacement
+		}
+		// позиции групп захвата идут парами после позиций всего совпадения
+		for group := 2; group+1 < len(match); group += 2 {
+			if match[group] < 0 {
+				submatch.Groups = append(submatch.Groups, nil)
+				continue
+			}
+			span := newJSONSpan(line.text, match[group], match[group+1])
+			submatch.Groups = append(submatch.Groups, &span)
+		}
+		submatches = append(submatches, submatch)
+	}
+
+	eventType := "context"
+	if selected {
+		eventType = "match"
+	}
+	writeEvent(p, eventType, jsonLine{
+		Path:           newJSONText(p.name),
+		Lines:          newJSONText(line.text + line.lineEnd),
+		LineNumber:     line.num,
+		AbsoluteOffset: line.offset,
+		Submatches:     submatches,
+	})
+}
+
+// begin - метод для вывода события begin перед первым событием файла
+func (p *jsonPrinter) begin() {
+	if !p.begun {
+		writeEvent(p, "begin", jsonBegin{Path: newJSONText(p.name)})
+		p.begun = true
+	}
+}
+
+// printBinaryMatch - реализация метода printBinaryMatch интерфейса iPrinter классом jsonPrinter
+// строки двоичного файла не выводятся, а смещение NUL передается в событии end
+func (p *jsonPrinter) printBinaryMatch(binaryOffset int) {
+	p.begin()
+	p.binaryOffset = &binaryOffset
+}
+
+// newJSONSpan - функция для создания совпадения в формате JSON по его позициям в строке
+func newJSONSpan(line string, start, end int) jsonSpan {
+	return jsonSpan{Match: newJSONText(line[start:end]), Start: start, End: end}
+}
+
+// finish - реализация метода finish интерфейса iPrinter классом jsonPrinter
+func (p *jsonPrinter) finish(stats *searchStats) error {
+	if p.begun {
+		// в статистику попадают байты, выведенные до события end
+		_ = p.Flush()
+		stats.bytesPrinted = p.output.count
+		writeEvent(p, "end", jsonEnd{Path: newJSONText(p.name), BinaryOffset: p.binaryOffset, Stats: newJSONStats(*stats)})
+	}
+	err := p.Flush()
+	stats.bytesPrinted = p.output.count
+	return err
+}
+
+// writeSummary - метод для вывода итогового события summary со статистикой по всем файлам
+func (s *searcher) writeSummary(w io.Writer, elapsed time.Duration) error {
+	writer := bufio.NewWriter(w)
+	writeEvent(writer, "summary", jsonSummary{ElapsedTotal: newJSONDuration(elapsed), Stats: newJSONStats(s.stats)})
+	return writer.Flush()
+}
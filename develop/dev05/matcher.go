@@ -0,0 +1,289 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// iMatcher - интерфейс для стратегии поиска совпадений в строке
+// findMatches возвращает позиции совпадений в байтах: начало и конец, за которыми могут идти позиции групп захвата
+// expand подставляет в шаблон совпадение и группы захвата, как regexp.Expand
+type iMatcher interface {
+	matchLine(string) bool
+	findMatches(string) [][]int
+	expand(template, line string, match []int) string
+}
+
+// iPositionalMatcher - интерфейс для стратегии поиска, умеющей искать совпадения с заданной позиции строки
+// нужен флагу -w, чтобы после отброшенного совпадения проверить более короткие и более поздние
+// findFrom возвращает первое совпадение, начинающееся не раньше позиции start, или nil
+// matchAt возвращает самое длинное совпадение, начинающееся в позиции start и заканчивающееся не дальше end, или nil
+type iPositionalMatcher interface {
+	findFrom(line string, start int) []int
+	matchAt(line string, start, end int) []int
+}
+
+// regexpMatcher - конкретная стратегия поиска строк по регулярному выражению
+// регистр при флаге -i учитывается самим регулярным выражением через (?i)
+type regexpMatcher struct {
+	re *regexp.Regexp
+
+	anchoredOnce sync.Once
+	anchored     *regexp.Regexp // то же выражение, привязанное к началу текста, создается при первом вызове matchAt
+}
+
+// matchLine - реализация метода matchLine интерфейса iMatcher классом regexpMatcher
+func (m *regexpMatcher) matchLine(line string) bool {
+	return m.re.MatchString(line)
+}
+
+// findMatches - реализация метода findMatches интерфейса iMatcher классом regexpMatcher
+// после позиций каждого совпадения идут позиции групп захвата
+func (m *regexpMatcher) findMatches(line string) [][]int {
+	return m.re.FindAllStringSubmatchIndex(line, -1)
+}
+
+// expand - реализация метода expand интерфейса iMatcher классом regexpMatcher
+func (m *regexpMatcher) expand(template, line string, match []int) string {
+	return string(m.re.ExpandString(nil, template, line, match))
+}
+
+// findFrom - реализация метода findFrom интерфейса iPositionalMatcher классом regexpMatcher
+// поиск идет по остатку строки, поэтому ^ и \b в позиции start проверяются как в начале текста
+func (m *regexpMatcher) findFrom(line string, start int) []int {
+	return shiftMatch(m.re.FindStringSubmatchIndex(line[start:]), start)
+}
+
+// matchAt - реализация метода matchAt интерфейса iPositionalMatcher классом regexpMatcher
+func (m *regexpMatcher) matchAt(line string, start, end int) []int {
+	m.anchoredOnce.Do(func() {
+		m.anchored = regexp.MustCompile(`^(?:` + m.re.String() + `)`)
+		m.anchored.Longest()
+	})
+	return shiftMatch(m.anchored.FindStringSubmatchIndex(line[start:end]), start)
+}
+
+// shiftMatch - функция для перевода позиций совпадения в подстроке, начинающейся с offset, в позиции строки
+// позиции -1 у не участвовавших в совпадении групп захвата не изменяются
+func shiftMatch(match []int, offset int) []int {
+	for i := range match {
+		if match[i] >= 0 {
+			match[i] += offset
+		}
+	}
+	return match
+}
+
+// fixedMatcher - конкретная стратегия поиска строк, содержащих одну из подстрок
+// набор подстрок ищется за один проход по строке автоматом Ахо-Корасик
+type fixedMatcher struct {
+	automaton  *ahoCorasick
+	ignoreCase bool
+}
+
+// newFixedMatcher - функция для создания стратегии поиска по набору строк
+func newFixedMatcher(queries []string, ignoreCase bool) *fixedMatcher {
+	// при флаге -i подстроки и строки приводятся к одному регистру
+	if ignoreCase {
+		foldedQueries := make([]string, len(queries))
+		for i, query := range queries {
+			foldedQueries[i], _ = foldCase(query, false)
+		}
+		queries = foldedQueries
+	}
+	return &fixedMatcher{automaton: newAhoCorasick(queries), ignoreCase: ignoreCase}
+}
+
+// matchLine - реализация метода matchLine интерфейса iMatcher классом fixedMatcher
+func (m *fixedMatcher) matchLine(line string) bool {
+	if m.ignoreCase {
+		line, _ = foldCase(line, false)
+	}
+	return m.automaton.contains(line)
+}
+
+// findMatches - реализация метода findMatches интерфейса iMatcher классом fixedMatcher
+func (m *fixedMatcher) findMatches(line string) [][]int {
+	if !m.ignoreCase {
+		return m.automaton.findAll(line)
+	}
+	// приведение к одному регистру может изменить длину символов в байтах,
+	// поэтому позиции совпадений переводятся обратно в позиции исходной строки
+	folded, offsets := foldCase(line, true)
+	matches := m.automaton.findAll(folded)
+	for _, match := range matches {
+		match[0], match[1] = offsets[match[0]], offsets[match[1]]
+	}
+	return matches
+}
+
+// findFrom - реализация метода findFrom интерфейса iPositionalMatcher классом fixedMatcher
+func (m *fixedMatcher) findFrom(line string, start int) []int {
+	matches := m.findMatches(line[start:])
+	if len(matches) == 0 {
+		return nil
+	}
+	return shiftMatch(matches[0], start)
+}
+
+// matchAt - реализация метода matchAt интерфейса iPositionalMatcher классом fixedMatcher
+func (m *fixedMatcher) matchAt(line string, start, end int) []int {
+	text := line[start:end]
+	if !m.ignoreCase {
+		length := m.automaton.longestPrefix(text)
+		if length < 0 {
+			return nil
+		}
+		return []int{start, start + length}
+	}
+	folded, offsets := foldCase(text, true)
+	length := m.automaton.longestPrefix(folded)
+	if length < 0 {
+		return nil
+	}
+	return []int{start, start + offsets[length]}
+}
+
+// literalRegexp - регулярное выражение без групп захвата для подстановки $0 в шаблон при поиске подстрок
+var literalRegexp = regexp.MustCompile("")
+
+// expand - реализация метода expand интерфейса iMatcher классом fixedMatcher
+// у подстрок нет групп захвата, поэтому в шаблон подставляется только совпадение целиком
+func (m *fixedMatcher) expand(template, line string, match []int) string {
+	return string(literalRegexp.ExpandString(nil, template, line, match))
+}
+
+// foldRune - функция для приведения символа к каноническому регистру
+// из всех вариантов символа в разных регистрах по таблицам Unicode выбирается наименьший
+func foldRune(r rune) rune {
+	folded := r
+	for c := unicode.SimpleFold(r); c != r; c = unicode.SimpleFold(c) {
+		folded = min(folded, c)
+	}
+	return folded
+}
+
+// foldCase - функция для приведения строки к каноническому регистру
+// при withOffsets дополнительно возвращается позиция в исходной строке для каждого байта результата и его конца
+func foldCase(s string, withOffsets bool) (string, []int) {
+	builder := strings.Builder{}
+	builder.Grow(len(s))
+	var offsets []int
+	if withOffsets {
+		offsets = make([]int, 0, len(s)+1)
+	}
+	for i, r := range s {
+		folded := foldRune(r)
+		// некорректные байты UTF-8 сохраняются без изменений
+		if r == utf8.RuneError {
+			_, size := utf8.DecodeRuneInString(s[i:])
+			builder.WriteString(s[i : i+size])
+			for ; withOffsets && size > 0; size-- {
+				offsets = append(offsets, i)
+			}
+			continue
+		}
+		builder.WriteRune(folded)
+		for size := utf8.RuneLen(folded); withOffsets && size > 0; size-- {
+			offsets = append(offsets, i)
+		}
+	}
+	if withOffsets {
+		offsets = append(offsets, len(s))
+	}
+	return builder.String(), offsets
+}
+
+// boundaryMatcher - декоратор стратегии поиска, оставляющий только совпадения на границах
+// при wholeLine совпадение должно занимать всю строку (флаг -x), иначе быть отдельным словом (флаг -w)
+type boundaryMatcher struct {
+	matcher   iMatcher
+	wholeLine bool
+}
+
+// matchLine - реализация метода matchLine интерфейса iMatcher классом boundaryMatcher
+func (m *boundaryMatcher) matchLine(line string) bool {
+	// быстрая проверка строк без совпадений
+	if !m.matcher.matchLine(line) {
+		return false
+	}
+	return len(m.findMatches(line)) > 0
+}
+
+// findMatches - реализация метода findMatches интерфейса iMatcher классом boundaryMatcher
+func (m *boundaryMatcher) findMatches(line string) [][]int {
+	// при поиске самого длинного совпадения строка, подходящая целиком, дает совпадение во всю строку
+	if m.wholeLine {
+		matches := m.matcher.findMatches(line)
+		if len(matches) > 0 && matches[0][0] == 0 && matches[0][1] == len(line) {
+			return matches[:1]
+		}
+		return nil
+	}
+	positional, ok := m.matcher.(iPositionalMatcher)
+	if !ok {
+		matches := m.matcher.findMatches(line)
+		words := matches[:0]
+		for _, match := range matches {
+			if isWordStart(line, match[0]) && isWordEnd(line, match[1]) {
+				words = append(words, match)
+			}
+		}
+		return words
+	}
+
+	// как и в grep, отброшенное совпадение не отменяет более короткие с того же начала и более поздние внутри него
+	words := make([][]int, 0)
+	for position := 0; position <= len(line); {
+		match := positional.findFrom(line, position)
+		if match == nil {
+			break
+		}
+		start := match[0]
+		// более короткие совпадения нужны, только если начало совпадения - граница слова
+		// конец совпадения сдвигается на целый символ, чтобы не разрезать многобайтовый символ UTF-8
+		for match != nil && match[1] > start && isWordStart(line, start) && !isWordEnd(line, match[1]) {
+			_, size := utf8.DecodeLastRuneInString(line[start:match[1]])
+			match = positional.matchAt(line, start, match[1]-size)
+		}
+		if match != nil && isWordStart(line, start) && isWordEnd(line, match[1]) {
+			words = append(words, match)
+			if match[1] > start {
+				position = match[1]
+				continue
+			}
+		}
+		// после пустого или отброшенного совпадения поиск продолжается со следующего символа
+		if start == len(line) {
+			break
+		}
+		_, size := utf8.DecodeRuneInString(line[start:])
+		position = start + size
+	}
+	return words
+}
+
+// expand - реализация метода expand интерфейса iMatcher классом boundaryMatcher
+func (m *boundaryMatcher) expand(template, line string, match []int) string {
+	return m.matcher.expand(template, line, match)
+}
+
+// isWordChar - функция для проверки, является ли символ частью слова: буква, цифра или '_'
+func isWordChar(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// isWordStart - функция для проверки, что перед позицией нет символа, являющегося частью слова
+func isWordStart(line string, position int) bool {
+	before, _ := utf8.DecodeLastRuneInString(line[:position])
+	return position == 0 || !isWordChar(before)
+}
+
+// isWordEnd - функция для проверки, что после позиции нет символа, являющегося частью слова
+func isWordEnd(line string, position int) bool {
+	after, _ := utf8.DecodeRuneInString(line[position:])
+	return position == len(line) || !isWordChar(after)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// searchResult - итог поиска в одном входном файле
+type searchResult struct {
+	stats  searchStats
+	failed bool // при поиске произошла ошибка, сообщение о ней уже выведено
+}
+
+// searchJob - задача поиска в одном входном файле
+type searchJob struct {
+	path   string
+	output *jobOutput
+	result searchResult
+	done   chan struct{} // закрывается после окончания поиска
+}
+
+// jobOutput - вывод задачи, который копится в буфере, пока до задачи не дойдет очередь,
+// а затем пишется напрямую, чтобы вывод большого файла не занимал память целиком
+type jobOutput struct {
+	mu      sync.Mutex
+	buffer  bytes.Buffer
+	direct  io.Writer // общий вывод, появляется, когда задача становится первой в очереди
+	prefix  string    // текст, выводимый перед первым байтом вывода задачи
+	written int       // количество байтов, записанных задачей
+}
+
+// Write - реализация метода Write интерфейса io.Writer классом jobOutput
+func (o *jobOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if o.direct == nil {
+		o.written += len(p)
+		return o.buffer.Write(p)
+	}
+	if err := o.writePrefix(); err != nil {
+		return 0, err
+	}
+	o.written += len(p)
+	return o.direct.Write(p)
+}
+
+// writePrefix - метод для вывода prefix перед первым байтом вывода задачи
+func (o *jobOutput) writePrefix() error {
+	if o.written > 0 || o.prefix == "" {
+		return nil
+	}
+	_, err := io.WriteString(o.direct, o.prefix)
+	return err
+}
+
+// attach - метод для переключения задачи на прямой вывод в w с выводом накопленного буфера
+func (o *jobOutput) attach(w io.Writer, prefix string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.direct, o.prefix = w, prefix
+	if o.buffer.Len() == 0 {
+		return nil
+	}
+	if _, err := io.WriteString(w, prefix); err != nil {
+		return err
+	}
+	_, err := o.buffer.WriteTo(w)
+	return err
+}
+
+// searchPool - класс для параллельного поиска в нескольких файлах с выводом результатов в порядке файлов
+type searchPool struct {
+	searcher *searcher
+	workers  int
+	output   io.Writer
+}
+
+// run - метод для поиска во всех входных файлах
+// handle вызывается для результатов в порядке входных файлов и возвращает false, если дальше искать не нужно
+func (p *searchPool) run(walker *inputWalker, paths []string, handle func(searchResult) bool) error {
+	jobs := make(chan *searchJob)
+	// ограничение количества задач, результаты которых ожидают своей очереди
+	ordered := make(chan *searchJob, p.workers)
+	var stopped atomic.Bool
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < p.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				job.result = searchPath(p.searcher, job.path, job.output)
+				close(job.done)
+			}
+		}()
+	}
+
+	var walkErr error
+	go func() {
+		walkErr = walker.walk(paths, func(path string) error {
+			if stopped.Load() {
+				return errStopSearch
+			}
+			job := &searchJob{path: path, output: &jobOutput{}, done: make(chan struct{})}
+			ordered <- job
+			jobs <- job
+			return nil
+		})
+		close(jobs)
+		close(ordered)
+	}()
+
+	// вывод результатов в порядке входных файлов
+	var outputErr error
+	printed := false
+	for job := range ordered {
+		if stopped.Load() || outputErr != nil {
+			<-job.done
+			continue
+		}
+		prefix := ""
+		if printed {
+			prefix = p.searcher.fileSeparator()
+		}
+		outputErr = job.output.attach(p.output, prefix)
+		<-job.done
+		printed = printed || job.output.written > 0
+		p.searcher.stats.add(job.result.stats)
+		if !handle(job.result) {
+			stopped.Store(true)
+		}
+	}
+	wg.Wait()
+
+	if outputErr != nil {
+		return outputErr
+	}
+	if walkErr != nil && !errors.Is(walkErr, errStopSearch) {
+		return walkErr
+	}
+	return nil
+}
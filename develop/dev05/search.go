@@ -0,0 +1,345 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// searchOptions - параметры вывода результатов поиска
+type searchOptions struct {
+	invert       bool // флаг -v, выбор строк без совпадений
+	count        bool // флаг -c, вывод только количества выбранных строк
+	lineNumbers  bool // флаг -n, вывод номеров строк
+	withFilename bool // вывод имени файла перед каждой строкой
+	before       int  // количество строк контекста перед выбранной строкой
+	after        int  // количество строк контекста после выбранной строки
+	onlyMatching bool // флаг -o, вывод только совпавших частей строк
+	byteOffset   bool // флаг -b, вывод смещения в байтах от начала файла
+	color        bool // подсветка совпадений, имен файлов и номеров строк
+	json         bool // вывод событий поиска в формате JSON, как у ripgrep
+	maxCount     int  // флаг -m, остановка чтения файла после указанного количества выбранных строк, 0 - без ограничения
+	listFiles    bool // флаги -l и -L, вывод только имен файлов
+	listMatching bool // при listFiles выводятся файлы с выбранными строками (-l), иначе без них (-L)
+	quiet        bool // флаг -q, поиск без вывода до первой выбранной строки
+	decompress   bool // флаг -z, поиск в распакованных данных сжатых файлов
+	binaryFiles  binaryMode
+	replace      bool   // ключ --replace, вывод совпадений, замененных по шаблону
+	template     string // шаблон замены с группами захвата $1, ${name}
+}
+
+// printsLines - метод для проверки, выводятся ли строки, или только итог по файлу
+func (o *searchOptions) printsLines() bool {
+	return !o.count && !o.listFiles && !o.quiet
+}
+
+// groupSeparator - разделитель несмежных групп строк при выводе контекста
+const groupSeparator = "--"
+
+// searcher - класс для потокового поиска строк во входных данных
+type searcher struct {
+	matcher iMatcher
+	opts    searchOptions
+	stats   searchStats // статистика поиска по всем файлам
+}
+
+// searchStats - статистика поиска в одном или нескольких файлах
+type searchStats struct {
+	elapsed           time.Duration
+	searches          int // количество просмотренных файлов
+	searchesWithMatch int // количество файлов с выбранными строками
+	bytesSearched     int
+	bytesPrinted      int
+	matchedLines      int // количество выбранных строк
+	matches           int // количество совпадений в выбранных строках
+}
+
+// add - метод для добавления статистики поиска в другом файле
+func (s *searchStats) add(other searchStats) {
+	s.elapsed += other.elapsed
+	s.searches += other.searches
+	s.searchesWithMatch += other.searchesWithMatch
+	s.bytesSearched += other.bytesSearched
+	s.bytesPrinted += other.bytesPrinted
+	s.matchedLines += other.matchedLines
+	s.matches += other.matches
+}
+
+// iPrinter - интерфейс для вывода результатов поиска в одном файле
+type iPrinter interface {
+	printLine(line inputLine, selected bool, matches [][]int)
+	printBinaryMatch(binaryOffset int)
+	finish(stats *searchStats) error
+}
+
+// newPrinter - метод для создания вывода результатов поиска в файле name в формате из параметров
+func (s *searcher) newPrinter(name string, w io.Writer) iPrinter {
+	output := &countingWriter{writer: w}
+	if s.opts.json {
+		return &jsonPrinter{Writer: bufio.NewWriter(output), output: output, searcher: s, name: name}
+	}
+	return &lineWriter{Writer: bufio.NewWriter(output), output: output, searcher: s, name: name}
+}
+
+// countingWriter - класс для подсчета выведенных байтов
+type countingWriter struct {
+	writer io.Writer
+	count  int
+}
+
+// Write - реализация метода Write интерфейса io.Writer классом countingWriter
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.writer.Write(p)
+	c.count += n
+	return n, err
+}
+
+// lineWriter - класс для текстового вывода строк одного файла с разделителями групп контекста
+type lineWriter struct {
+	*bufio.Writer
+	output      *countingWriter
+	searcher    *searcher
+	name        string
+	lastPrinted int // номер последней выведенной строки, 0 - строки еще не выводились
+}
+
+// inputLine - строка входных данных с ее номером и смещением от начала файла
+type inputLine struct {
+	num     int
+	offset  int
+	text    string
+	lineEnd string // перевод строки, которым заканчивалась строка, пустой для последней строки без него
+}
+
+// fileSeparator - метод для получения разделителя между выводом разных файлов
+// разделитель нужен, только если выводятся строки контекста
+func (s *searcher) fileSeparator() string {
+	if s.opts.before == 0 && s.opts.after == 0 || !s.opts.printsLines() || s.opts.json {
+		return ""
+	}
+	if s.opts.color {
+		return colorSeparator + groupSeparator + colorReset + "\n"
+	}
+	return groupSeparator + "\n"
+}
+
+// search - метод для построчного поиска в r с записью результата в w
+// возвращает статистику поиска, количество выбранных строк в ней - matchedLines
+func (s *searcher) search(name string, r io.Reader, w io.Writer) (searchStats, error) {
+	started := time.Now()
+	reader := bufio.NewReaderSize(r, binaryPeekSize)
+	printer := s.newPrinter(name, w)
+	// позиции совпадений нужны только для их вывода или подсветки
+	findMatches := (s.opts.onlyMatching || s.opts.color || s.opts.json || s.opts.replace) && !s.opts.invert
+
+	stats := searchStats{searches: 1}
+	selected := 0
+	before := make([]inputLine, 0, s.opts.before) // последние строки перед выбранной строкой
+	afterLeft := 0                                // количество строк контекста, которые осталось вывести
+	offset := 0
+	var readErr error
+
+	// проверка начала файла на двоичные данные, при политике without-match такие файлы не читаются
+	detector := binaryDetector{}
+	if s.opts.binaryFiles != binaryText {
+		detector.checkHeader(reader)
+	}
+	for num := 1; !detector.binary || s.opts.binaryFiles != binaryWithoutMatch; num++ {
+		// после достижения ограничения -m дочитываются только строки контекста после последней выбранной
+		limitReached := s.opts.maxCount > 0 && selected >= s.opts.maxCount
+		if limitReached && afterLeft == 0 {
+			break
+		}
+		text, err := reader.ReadString('\n')
+		// при ошибке чтения выводятся результаты по уже прочитанным строкам
+		if err != nil && err != io.EOF {
+			readErr = err
+			break
+		}
+		// строка без перевода строки в конце файла тоже обрабатывается
+		if err == io.EOF && text == "" {
+			break
+		}
+		line := inputLine{num: num, offset: offset, text: strings.TrimSuffix(text, "\n")}
+		line.lineEnd = text[len(line.text):]
+		offset += len(text)
+		// NUL может встретиться и после начала файла
+		if s.opts.binaryFiles != binaryText {
+			detector.checkLine(line)
+		}
+		if detector.binary && s.opts.binaryFiles == binaryWithoutMatch {
+			break
+		}
+
+		if limitReached {
+			afterLeft--
+			if !detector.binary {
+				printer.printLine(line, false, nil)
+			}
+			if err == io.EOF {
+				break
+			}
+			continue
+		}
+
+		var matches [][]int
+		isMatch := false
+		if findMatches {
+			matches = s.matcher.findMatches(line.text)
+			isMatch = len(matches) > 0
+		} else {
+			isMatch = s.matcher.matchLine(line.text)
+		}
+
+		// строка выбирается, если она подходит под запрос, а при флаге -v - если не подходит
+		if isMatch != s.opts.invert {
+			selected++
+			stats.matches += len(matches)
+			// вместо строк двоичного файла выводится сообщение о совпадении, и дальше файл можно не читать
+			if detector.binary && s.opts.printsLines() {
+				printer.printBinaryMatch(detector.offset)
+				break
+			}
+			if s.opts.printsLines() {
+				// вывод накопленного контекста перед строкой
+				for _, contextLine := range before {
+					printer.printLine(contextLine, false, nil)
+				}
+				before = before[:0]
+				printer.printLine(line, true, matches)
+			}
+			afterLeft = s.opts.after
+		} else if afterLeft > 0 && !detector.binary {
+			afterLeft--
+			printer.printLine(line, false, nil)
+		} else if s.opts.before > 0 {
+			// сохранение строки как возможного контекста перед следующей выбранной строкой
+			if len(before) == s.opts.before {
+				before = append(before[:0], before[1:]...)
+			}
+			before = append(before, line)
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	stats.bytesSearched = offset
+	stats.matchedLines = selected
+	if selected > 0 {
+		stats.searchesWithMatch = 1
+	}
+	stats.elapsed = time.Since(started)
+	err := printer.finish(&stats)
+	if readErr != nil {
+		return stats, readErr
+	}
+	return stats, err
+}
+
+// printBinaryMatch - реализация метода printBinaryMatch интерфейса iPrinter классом lineWriter
+func (w *lineWriter) printBinaryMatch(int) {
+	_, _ = w.WriteString("Binary file " + w.name + " matches\n")
+}
+
+// finish - реализация метода finish интерфейса iPrinter классом lineWriter
+func (w *lineWriter) finish(stats *searchStats) error {
+	opts := w.searcher.opts
+	switch {
+	case opts.quiet:
+	// если были введены флаги -l или -L, вывод имени файла с выбранными строками или без них
+	case opts.listFiles:
+		if (stats.matchedLines > 0) == opts.listMatching {
+			w.writeColored(w.name, colorFilename)
+			_, _ = w.WriteString("\n")
+		}
+	// если был введен флаг -c, вывод количества выбранных строк
+	case opts.count:
+		if opts.withFilename {
+			w.writeColored(w.name, colorFilename)
+			w.writeColored(":", colorSeparator)
+		}
+		_, _ = w.WriteString(strconv.Itoa(stats.matchedLines) + "\n")
+	}
+	err := w.Flush()
+	stats.bytesPrinted = w.output.count
+	return err
+}
+
+// printLine - реализация метода printLine интерфейса iPrinter классом lineWriter
+// выбранные строки отмечаются символом ':', строки контекста - символом '-'
+func (w *lineWriter) printLine(line inputLine, selected bool, matches [][]int) {
+	opts := w.searcher.opts
+	// вывод разделителя перед группой, не примыкающей к предыдущей
+	if (opts.before > 0 || opts.after > 0) && w.lastPrinted != 0 && line.num != w.lastPrinted+1 {
+		w.writeColored(groupSeparator, colorSeparator)
+		_, _ = w.WriteString("\n")
+	}
+	w.lastPrinted = line.num
+
+	mark := "-"
+	if selected {
+		mark = ":"
+	}
+	// если был введен флаг -o, каждое непустое совпадение выводится отдельной строкой со своим смещением
+	if opts.onlyMatching {
+		for _, match := range matches {
+			if match[0] == match[1] {
+				continue
+			}
+			w.writePrefix(line.num, line.offset+match[0], mark)
+			w.writeColored(w.matchText(line.text, match), colorMatch)
+			_, _ = w.WriteString("\n")
+		}
+		return
+	}
+
+	w.writePrefix(line.num, line.offset, mark)
+	// подсветка совпадений, если они были найдены
+	start := 0
+	for _, match := range matches {
+		_, _ = w.WriteString(line.text[start:match[0]])
+		w.writeColored(w.matchText(line.text, match), colorMatch)
+		start = match[1]
+	}
+	_, _ = w.WriteString(line.text[start:] + "\n")
+}
+
+// matchText - метод для получения текста совпадения, при --replace - результата подстановки в шаблон
+func (w *lineWriter) matchText(line string, match []int) string {
+	if w.searcher.opts.replace {
+		return w.searcher.matcher.expand(w.searcher.opts.template, line, match)
+	}
+	return line[match[0]:match[1]]
+}
+
+// writePrefix - метод для вывода имени файла, номера строки и смещения перед строкой
+func (w *lineWriter) writePrefix(num, offset int, mark string) {
+	opts := w.searcher.opts
+	if opts.withFilename {
+		w.writeColored(w.name, colorFilename)
+		w.writeColored(mark, colorSeparator)
+	}
+	// если был введен флаг -n, вывод номера строки перед самой строкой
+	if opts.lineNumbers {
+		w.writeColored(strconv.Itoa(num), colorNumber)
+		w.writeColored(mark, colorSeparator)
+	}
+	// если был введен флаг -b, вывод смещения в байтах
+	if opts.byteOffset {
+		w.writeColored(strconv.Itoa(offset), colorNumber)
+		w.writeColored(mark, colorSeparator)
+	}
+}
+
+// writeColored - метод для вывода текста, подсвеченного при включенном цвете
+func (w *lineWriter) writeColored(text, color string) {
+	if !w.searcher.opts.color || text == "" {
+		_, _ = w.WriteString(text)
+		return
+	}
+	_, _ = w.WriteString(color + text + colorReset)
+}
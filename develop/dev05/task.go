@@ -2,358 +2,390 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"regexp"
+	"runtime"
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 )
 
-func main() {
-	filename := os.Args[2] // получение пути к файлу для обработки
+// коды возврата, как у grep
+const (
+	exitMatch   = 0 // найдена хотя бы одна строка
+	exitNoMatch = 1 // строки не найдены
+	exitError   = 2 // произошла ошибка
+)
 
-	// открытие выбранного файла для чтения и записи
-	file, err := os.OpenFile(filename, os.O_RDWR, os.FileMode(0755))
-	if err != nil {
-		log.Fatalln("file opening error, file:", filename, err)
-	}
+// fatal - функция для вывода ошибки и завершения программы с кодом exitError
+func fatal(v ...any) {
+	log.Println(v...)
+	os.Exit(exitError)
+}
 
-	// закрытие файла в defer, чтобы избежать утечки
-	defer func(file *os.File) {
-		err := file.Close()
-		if err != nil {
+// errStopSearch - ошибка для остановки обхода файлов, когда результат уже известен
+var errStopSearch = errors.New("search stopped")
+
+// valueFlags - ключи, за которыми следует значение
+var valueFlags = []string{"-A", "-B", "-C", "-e", "-f", "-m", "-j", "--replace"}
 
+// numberFlags - ключи, значением которых является неотрицательное число
+var numberFlags = []string{"-A", "-B", "-C", "-m", "-j"}
+
+// commandLine - разобранные аргументы командной строки
+type commandLine struct {
+	flags    []string            // ключи без значений, например -v или --color=always
+	values   map[string][]string // значения ключей из valueFlags в порядке их появления
+	operands []string            // аргументы, не являющиеся ключами и их значениями
+}
+
+// parseCommandLine - функция для разбора аргументов командной строки за один проход
+// значение ключа из valueFlags никогда не считается ключом, поэтому запрос может начинаться с "-", например -e -v
+func parseCommandLine(args []string) (*commandLine, error) {
+	c := &commandLine{values: map[string][]string{}}
+	for argIndex := 0; argIndex < len(args); argIndex++ {
+		arg := args[argIndex]
+		switch {
+		case slices.Contains(valueFlags, arg):
+			if len(args) <= argIndex+1 {
+				return nil, fmt.Errorf("not enough arguments given after %s flag", arg)
+			}
+			argIndex++
+			if err := c.addValue(arg, args[argIndex]); err != nil {
+				return nil, err
+			}
+		// как и в grep, значение короткого ключа может идти сразу после него, например -A2, -m1 или -epattern
+		case len(arg) > 2 && slices.Contains(valueFlags, arg[:2]):
+			if err := c.addValue(arg[:2], arg[2:]); err != nil {
+				return nil, err
+			}
+		case arg == "-" || !strings.HasPrefix(arg, "-"):
+			c.operands = append(c.operands, arg)
+		default:
+			c.flags = append(c.flags, arg)
 		}
-	}(file)
+	}
+	return c, nil
+}
 
-	scanner := bufio.NewScanner(file) // создание сканера для чтения файла
+// addValue - метод для добавления значения ключа, числовые значения проверяются сразу
+func (c *commandLine) addValue(flag, value string) error {
+	if slices.Contains(numberFlags, flag) {
+		if number, err := strconv.Atoi(value); err != nil || number < 0 {
+			return fmt.Errorf("incorrect value given after %s flag: %s", flag, value)
+		}
+	}
+	c.values[flag] = append(c.values[flag], value)
+	return nil
+}
 
-	scanner.Split(bufio.ScanLines) // установка функции разделения для чтения по строкам
+// hasFlag - метод для проверки, был ли указан ключ без значения, например -v
+func (c *commandLine) hasFlag(flag string) bool {
+	return slices.Contains(c.flags, flag)
+}
 
-	lines := make([]string, 0) // создание слайса строк
+// flagValues - метод для получения значений всех вхождений ключа, например -e a -e b
+func (c *commandLine) flagValues(flag string) []string {
+	return slices.Clone(c.values[flag])
+}
 
-	// чтение строк из файла
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
+// flagNumber - метод для получения числового значения ключа, например -A 2, при повторе ключа берется последнее
+func (c *commandLine) flagNumber(flag string) (int, bool) {
+	values := c.values[flag]
+	if len(values) == 0 {
+		return 0, false
 	}
+	// значение уже проверено при разборе аргументов
+	value, _ := strconv.Atoi(values[len(values)-1])
+	return value, true
+}
 
-	if err = scanner.Err(); err != nil {
-		log.Fatalln(err)
+// longFlagValues - метод для получения всех значений ключа в формате --name=value
+func (c *commandLine) longFlagValues(name string) []string {
+	values := make([]string, 0)
+	for _, flag := range c.flags {
+		if strings.HasPrefix(flag, name+"=") {
+			values = append(values, strings.TrimPrefix(flag, name+"="))
+		}
 	}
+	return values
+}
 
-	// проверка опциональных флагов
-	count := slices.Contains(os.Args[3:], "-c")
+// getContext - функция для получения количества строк контекста из ключей -C, -A и -B
+// ключи -A и -B можно указывать вместе, они имеют приоритет над -C
+func getContext(args *commandLine) (int, int) {
+	before, _ := args.flagNumber("-C")
+	after := before
+	if lines, ok := args.flagNumber("-B"); ok {
+		before = lines
+	}
+	if lines, ok := args.flagNumber("-A"); ok {
+		after = lines
+	}
+	return before, after
+}
 
-	ignoreCase := slices.Contains(os.Args[3:], "-i")
+// readPatterns - функция для чтения запросов из файла, по одному запросу в строке, "-" означает stdin
+func readPatterns(path string) []string {
+	input := os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			fatal("file opening error, file:", path, err)
+		}
+		// закрытие файла в defer, чтобы избежать утечки
+		defer func(file *os.File) {
+			_ = file.Close()
+		}(file)
+		input = file
+	}
 
-	invert := slices.Contains(os.Args[3:], "-v")
+	patterns := make([]string, 0)
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		patterns = append(patterns, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		fatal("file reading error, file:", path, err)
+	}
+	return patterns
+}
 
-	fixed := slices.Contains(os.Args[3:], "-F")
+// getPatterns - функция для получения запросов из ключей -e и -f
+// если ключей нет, запросом считается первый операнд, возвращаются запросы и оставшиеся операнды
+func getPatterns(args *commandLine) ([]string, []string) {
+	operands := args.operands
+	patterns := args.flagValues("-e")
+	patternFiles := args.flagValues("-f")
+	for _, path := range patternFiles {
+		patterns = append(patterns, readPatterns(path)...)
+	}
+	if len(patterns) > 0 || len(patternFiles) > 0 {
+		return patterns, operands
+	}
+	if len(operands) == 0 {
+		fatal("no search query given")
+	}
+	return operands[:1], operands[1:]
+}
 
-	numbers := slices.Contains(os.Args[3:], "-n")
+// newMatcher - функция для создания стратегии поиска по запросам и ключам -F, -i, -w и -x
+func newMatcher(patterns []string, args *commandLine) iMatcher {
+	ignoreCase := args.hasFlag("-i")
 
-	// получение строки для поиска
-	searchQuery := os.Args[1]
-	// проверка флага -i
-	if fixed {
-		searchQuery = strings.ToLower(searchQuery) // приведение строки для поиска к нижнему регистру
+	var matcher iMatcher
+	// проверка флага -F, запросы ищутся как подстроки
+	if args.hasFlag("-F") {
+		matcher = newFixedMatcher(patterns, ignoreCase)
+	} else {
+		// объединение запросов в одно регулярное выражение, чтобы проверять строку за один проход
+		alternatives := make([]string, len(patterns))
+		for i, pattern := range patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				fatal(err)
+			}
+			alternatives[i] = "(?:" + pattern + ")"
+		}
+		// без запросов, например при пустом файле для -f, ни одна строка не подходит
+		if len(alternatives) == 0 {
+			alternatives = append(alternatives, `[^\x00-\x{10FFFF}]`)
+		}
+		expression := strings.Join(alternatives, "|")
+		// проверка флага -i, регулярное выражение сравнивает символы с учетом регистров Unicode
+		if ignoreCase {
+			expression = "(?i)" + expression
+		}
+		re, err := regexp.Compile(expression) // создание регулярного выражения для поиска строк
+		if err != nil {
+			fatal(err)
+		}
+		// как и в grep, выбирается самое длинное из совпадений, начинающихся в одной позиции
+		re.Longest()
+		matcher = &regexpMatcher{re: re}
 	}
 
-	re, err := regexp.Compile(searchQuery) // создание регулярного выражения для поиска строк
-	if err != nil {
-		log.Fatalln(err)
+	// проверка флагов -x и -w
+	switch {
+	case args.hasFlag("-x"):
+		matcher = &boundaryMatcher{matcher: matcher, wholeLine: true}
+	case args.hasFlag("-w"):
+		matcher = &boundaryMatcher{matcher: matcher}
 	}
+	return matcher
+}
 
-	// переключение по основным флагам
-	switch {
-	// проверка на флаг -C
-	case slices.Contains(os.Args[3:], "-C"):
-		linesToPrint := make([]int, 0)          // создание слайса для номеров строк файла, которые нужно вывести
-		lineStrings := make(map[int]string)     // создание map для хранения содержимого строк
-		argIndex := slices.Index(os.Args, "-C") // получение позиции флага -C
-		// проверка количества аргументов
-		if len(os.Args) > argIndex+1 {
-			// получение количества строк контекста
-			argParam := os.Args[argIndex+1]
-			linesContext, err := strconv.Atoi(argParam)
-			if err != nil {
-				log.Fatalln(err)
-			}
-			// если запрос инвертирован, заполнение слайса и map всеми строками файла
-			if invert {
-				for i := 0; i < len(lines); i++ {
-					linesToPrint = append(linesToPrint, i)
-					lineStrings[i] = lines[i]
-				}
-			}
-			// цикл для поиска по строкам
-			for lineNum, line := range lines {
-				// если был установлен флаг -i, приведение строки к нижнему регистру
-				if ignoreCase {
-					line = strings.ToLower(line)
-				}
-				var matches [][]byte
-				// переключение проверки строки относительно флага -F
-				if fixed {
-					// проверка совпадения строки с запросом поиска
-					if line == searchQuery {
-						matches = [][]byte{[]byte("yes")}
-					}
-				} else {
-					matches = re.FindAll([]byte(line), -1) // проверка совпадения строки с регулярным выражением
-				}
-				// если строка подходит под условие
-				if len(matches) > 0 {
-					// если был введен флаг -i, постепенно удаляются подходящие строки из слайса и map
-					if invert {
-						// цикл для всех строк в контексте
-						for num := max(0, lineNum-linesContext); num < min(len(lines)-1, lineNum+linesContext)+1; num++ {
-							delete(lineStrings, num) // удаление из map
-							// удаление из слайса
-							index := slices.Index(linesToPrint, num)
-							if index != -1 {
-								linesToPrint = slices.Delete(linesToPrint, index, index+1)
-							}
-						}
-						// если флага -i не было, заполнения слайса и map подходящими строками
-					} else {
-						// цикл для всех строк в контексте
-						for num := max(0, lineNum-linesContext); num < min(len(lines)-1, lineNum+linesContext)+1; num++ {
-							linesToPrint = append(linesToPrint, num) // добавление номера строки в слайс
-							lineStrings[num] = lines[num]            // добавление строки в map
-						}
-					}
-				}
+// searchPath - функция для поиска в одном входном файле, "-" означает stdin
+// сообщение об ошибке выводится сразу, в результате остается только признак ошибки
+func searchPath(s *searcher, path string, w io.Writer) searchResult {
+	name, input := stdinName, io.Reader(os.Stdin)
+	if path != "-" {
+		// открытие файла только для чтения
+		file, err := os.Open(path)
+		if err != nil {
+			log.Println("file opening error, file:", path, err)
+			return searchResult{failed: true}
+		}
+		// закрытие файла в defer, чтобы избежать утечки
+		defer func(file *os.File) {
+			_ = file.Close()
+		}(file)
+		name, input = path, file
+	}
 
-			}
-			slices.Sort(linesToPrint)                   // сортировка слайса с номерами строк
-			linesToPrint = slices.Compact(linesToPrint) // удаление повторяющихся номеров строк
-			if count {
-				fmt.Println(len(linesToPrint)) // если был введен флаг -c, вывод количества подходящих строк
-			} else {
-				// если не был введен флаг -c вывод подходящих строк
-				for num, line := range linesToPrint {
-					if numbers {
-						fmt.Print(strconv.Itoa(num) + ":") // если был введен флаг -n, вывод номера строки перед самой строкой
-					}
-					fmt.Println(lines[line])
-				}
-			}
-		} else {
-			log.Fatalln("incorrect parameters given")
+	// если был введен флаг -z, сжатые данные распаковываются, а в выводе остается исходное имя файла
+	if s.opts.decompress {
+		reader, err := decompress(input)
+		if err != nil {
+			log.Println("file decompression error, file:", name, err)
+			return searchResult{failed: true}
 		}
-	// проверка на флаг -A
-	case slices.Contains(os.Args[3:], "-A"):
-		linesToPrint := make([]int, 0)          // создание слайса для номеров строк файла, которые нужно вывести
-		lineStrings := make(map[int]string)     // создание map для хранения содержимого строк
-		argIndex := slices.Index(os.Args, "-C") // получение позиции флага -C
-		// проверка количества аргументов
-		if len(os.Args) > argIndex+1 {
-			// получение количества строк контекста
-			argParam := os.Args[argIndex+1]
-			linesContext, err := strconv.Atoi(argParam)
-			if err != nil {
-				log.Fatalln(err)
-			}
-			// если запрос инвертирован, заполнение слайса и map всеми строками файла
-			if invert {
-				for i := 0; i < len(lines); i++ {
-					linesToPrint = append(linesToPrint, i)
-					lineStrings[i] = lines[i]
-				}
-			}
-			// цикл для поиска по строкам
-			for lineNum, line := range lines {
-				// если был установлен флаг -i, приведение строки к нижнему регистру
-				if ignoreCase {
-					line = strings.ToLower(line)
-				}
-				var matches [][]byte
-				// переключение проверки строки относительно флага -F
-				if fixed {
-					// проверка совпадения строки с запросом поиска
-					if line == searchQuery {
-						matches = [][]byte{[]byte("yes")}
-					}
-				} else {
-					matches = re.FindAll([]byte(line), -1) // проверка совпадения строки с регулярным выражением
-				}
-				// если строка подходит под условие
-				if len(matches) > 0 {
-					// если был введен флаг -i, постепенно удаляются подходящие строки из слайса и map
-					if invert {
-						// цикл для всех строк после найденной, включая ее саму
-						for num := lineNum; num < min(len(lines)-1, lineNum+linesContext)+1; num++ {
-							delete(lineStrings, num) // удаление из map
-							// удаление из слайса
-							index := slices.Index(linesToPrint, num)
-							if index != -1 {
-								linesToPrint = slices.Delete(linesToPrint, index, index+1)
-							}
-						}
-						// если флага -i не было, заполнения слайса и map подходящими строками
-					} else {
-						// цикл для всех строк после найденной, включая ее саму
-						for num := lineNum; num < min(len(lines)-1, lineNum+linesContext)+1; num++ {
-							linesToPrint = append(linesToPrint, num) // добавление номера строки в слайс
-							lineStrings[num] = lines[num]            // добавление строки в map
-						}
-					}
-				}
+		defer func(reader io.ReadCloser) {
+			_ = reader.Close()
+		}(reader)
+		input = reader
+	}
 
-			}
-			slices.Sort(linesToPrint)                   // сортировка слайса с номерами строк
-			linesToPrint = slices.Compact(linesToPrint) // удаление повторяющихся номеров строк
-			if count {
-				fmt.Println(len(linesToPrint)) // если был введен флаг -c, вывод количества подходящих строк
-			} else {
-				// если не был введен флаг -c вывод подходящих строк
-				for num, line := range linesToPrint {
-					if numbers {
-						fmt.Print(strconv.Itoa(num) + ":") // если был введен флаг -n, вывод номера строки перед самой строкой
-					}
-					fmt.Println(lines[line])
-				}
-			}
+	stats, err := s.search(name, input, w)
+	if err != nil {
+		log.Println("file reading error, file:", name, err)
+	}
+	return searchResult{stats: stats, failed: err != nil}
+}
+
+func main() {
+	// получение запросов для поиска и путей к входным файлам
+	args, err := parseCommandLine(os.Args[1:])
+	if err != nil {
+		fatal(err)
+	}
+	patterns, paths := getPatterns(args)
+
+	walker := &inputWalker{
+		recursive:   args.hasFlag("-r"),
+		includes:    args.longFlagValues("--include"),
+		excludes:    args.longFlagValues("--exclude"),
+		excludeDirs: args.longFlagValues("--exclude-dir"),
+	}
+	// если файлы не указаны, поиск идет в текущей директории при -r или в stdin
+	if len(paths) == 0 {
+		if walker.recursive {
+			paths = append(paths, ".")
 		} else {
-			log.Fatalln("incorrect parameters given")
+			paths = append(paths, "-")
 		}
-	// проверка на флаг -B
-	case slices.Contains(os.Args[3:], "-B"):
-		linesToPrint := make([]int, 0)          // создание слайса для номеров строк файла, которые нужно вывести
-		lineStrings := make(map[int]string)     // создание map для хранения содержимого строк
-		argIndex := slices.Index(os.Args, "-C") // получение позиции флага -C
-		// проверка количества аргументов
-		if len(os.Args) > argIndex+1 {
-			// получение количества строк контекста
-			argParam := os.Args[argIndex+1]
-			linesContext, err := strconv.Atoi(argParam)
-			if err != nil {
-				log.Fatalln(err)
-			}
-			// если запрос инвертирован, заполнение слайса и map всеми строками файла
-			if invert {
-				for i := 0; i < len(lines); i++ {
-					linesToPrint = append(linesToPrint, i)
-					lineStrings[i] = lines[i]
-				}
-			}
-			// цикл для поиска по строкам
-			for lineNum, line := range lines {
-				// если был установлен флаг -i, приведение строки к нижнему регистру
-				if ignoreCase {
-					line = strings.ToLower(line)
-				}
-				var matches [][]byte
-				// переключение проверки строки относительно флага -F
-				if fixed {
-					// проверка совпадения строки с запросом поиска
-					if line == searchQuery {
-						matches = [][]byte{[]byte("yes")}
-					}
-				} else {
-					matches = re.FindAll([]byte(line), -1) // проверка совпадения строки с регулярным выражением
-				}
-				// если строка подходит под условие
-				if len(matches) > 0 {
-					// если был введен флаг -i, постепенно удаляются подходящие строки из слайса и map
-					if invert {
-						// цикл для всех строк перед найденной, включая ее саму
-						for num := max(0, lineNum-linesContext); num < lineNum+1; num++ {
-							delete(lineStrings, num) // удаление из map
-							// удаление из слайса
-							index := slices.Index(linesToPrint, num)
-							if index != -1 {
-								linesToPrint = slices.Delete(linesToPrint, index, index+1)
-							}
-						}
-						// если флага -i не было, заполнения слайса и map подходящими строками
-					} else {
-						// цикл для всех строк перед найденной, включая ее саму
-						for num := max(0, lineNum-linesContext); num < lineNum+1; num++ {
-							linesToPrint = append(linesToPrint, num) // добавление номера строки в слайс
-							lineStrings[num] = lines[num]            // добавление строки в map
-						}
-					}
-				}
+	}
 
-			}
-			slices.Sort(linesToPrint)                   // сортировка слайса с номерами строк
-			linesToPrint = slices.Compact(linesToPrint) // удаление повторяющихся номеров строк
-			if count {
-				fmt.Println(len(linesToPrint)) // если был введен флаг -c, вывод количества подходящих строк
-			} else {
-				// если не был введен флаг -c вывод подходящих строк
-				for num, line := range linesToPrint {
-					if numbers {
-						fmt.Print(strconv.Itoa(num) + ":") // если был введен флаг -n, вывод номера строки перед самой строкой
-					}
-					fmt.Println(lines[line])
-				}
-			}
-		} else {
-			log.Fatalln("incorrect parameters given")
+	// проверка опциональных флагов
+	opts := searchOptions{
+		invert:       args.hasFlag("-v"),
+		count:        args.hasFlag("-c"),
+		lineNumbers:  args.hasFlag("-n"),
+		onlyMatching: args.hasFlag("-o"),
+		byteOffset:   args.hasFlag("-b"),
+		json:         args.hasFlag("--json"),
+		listFiles:    args.hasFlag("-l") || args.hasFlag("-L"),
+		listMatching: args.hasFlag("-l"),
+		quiet:        args.hasFlag("-q"),
+		decompress:   args.hasFlag("-z"),
+		// имя файла выводится, если входных файлов может быть несколько
+		withFilename: len(paths) > 1 || (walker.recursive && hasDirectory(paths)),
+	}
+	// проверка на ключ -m, при -m 0 файлы не читаются
+	if maxCount, ok := args.flagNumber("-m"); ok {
+		if maxCount == 0 {
+			os.Exit(exitNoMatch)
 		}
-	// стандартный случай, если флагов -A -B -C нет
-	default:
-		linesToPrint := make([]int, 0)      // создание слайса для номеров строк файла, которые нужно вывести
-		lineStrings := make(map[int]string) // создание map для хранения содержимого строк
-		// если запрос инвертирован, заполнение слайса и map всеми строками файла
-		if invert {
-			for i := 0; i < len(lines); i++ {
-				linesToPrint = append(linesToPrint, i)
-				lineStrings[i] = lines[i]
-			}
+		opts.maxCount = maxCount
+	}
+	// при флагах -l, -L и -q чтение файла прекращается на первой выбранной строке
+	if opts.listFiles || opts.quiet {
+		opts.maxCount = 1
+		opts.count, opts.json = false, false
+	}
+	// в формате JSON выводятся строки целиком вместе с позициями совпадений
+	if opts.json {
+		opts.count, opts.onlyMatching = false, false
+	}
+	// при флагах -c, -l, -L, -q и -o контекст не выводится
+	if opts.printsLines() && !opts.onlyMatching {
+		opts.before, opts.after = getContext(args)
+	}
+	// проверка на ключ --replace TEMPLATE или --replace=TEMPLATE, последнее значение имеет приоритет
+	if templates := append(args.flagValues("--replace"), args.longFlagValues("--replace")...); len(templates) > 0 {
+		opts.replace, opts.template = true, templates[len(templates)-1]
+	}
+	// проверка на ключ --color, без значения подсветка включается только для терминала
+	colorMode := "never"
+	if args.hasFlag("--color") {
+		colorMode = "auto"
+	}
+	if modes := args.longFlagValues("--color"); len(modes) > 0 {
+		colorMode = modes[len(modes)-1]
+	}
+	color, err := parseColorMode(colorMode, os.Stdout)
+	if err != nil {
+		fatal(err)
+	}
+	opts.color = color && !opts.json
+
+	// проверка на ключи --binary-files=TYPE и -a
+	if modes := args.longFlagValues("--binary-files"); len(modes) > 0 {
+		if opts.binaryFiles, err = parseBinaryMode(modes[len(modes)-1]); err != nil {
+			fatal(err)
 		}
-		// цикл для поиска по строкам
-		for lineNum, line := range lines {
-			// если был установлен флаг -i, приведение строки к нижнему регистру
-			if ignoreCase {
-				line = strings.ToLower(line)
-			}
-			var matches [][]byte
-			// переключение проверки строки относительно флага -F
-			if fixed {
-				// проверка совпадения строки с запросом поиска
-				if line == searchQuery {
-					matches = [][]byte{[]byte("yes")}
-				}
-			} else {
-				matches = re.FindAll([]byte(line), -1) // проверка совпадения строки с регулярным выражением
-			}
-			// если строка подходит под условие
-			if len(matches) > 0 {
-				// если был введен флаг -i, постепенно удаляются подходящие строки из слайса и map
-				if invert {
-					delete(lineStrings, lineNum) // удаление из map
-					// удаление из слайса
-					index := slices.Index(linesToPrint, lineNum)
-					if index != -1 {
-						linesToPrint = slices.Delete(linesToPrint, index, index+1)
-					}
-					// если флага -i не было, заполнения слайса и map подходящими строками
-				} else {
-					linesToPrint = append(linesToPrint, lineNum) // добавление номера строки в слайс
-					lineStrings[lineNum] = lines[lineNum]        // добавление строки в map
-				}
-			}
+	}
+	if args.hasFlag("-a") {
+		opts.binaryFiles = binaryText
+	}
 
+	// получение количества файлов, в которых поиск идет одновременно, из ключа -j
+	workers := runtime.NumCPU()
+	if value, ok := args.flagNumber("-j"); ok {
+		if value == 0 {
+			fatal("number of jobs must be positive")
 		}
-		slices.Sort(linesToPrint)                   // сортировка слайса с номерами строк
-		linesToPrint = slices.Compact(linesToPrint) // удаление повторяющихся номеров строк
-		if count {
-			fmt.Println(len(linesToPrint)) // если был введен флаг -c, вывод количества подходящих строк
+		workers = value
+	}
+
+	started := time.Now()
+	s := &searcher{matcher: newMatcher(patterns, args), opts: opts}
+	pool := &searchPool{searcher: s, workers: workers, output: os.Stdout}
+	found, failed := false, false
+	err = pool.run(walker, paths, func(result searchResult) bool {
+		failed = failed || result.failed
+		// при -L успехом считается вывод имени файла без выбранных строк
+		if opts.listFiles && !opts.listMatching {
+			found = found || (result.stats.matchedLines == 0 && !result.failed)
 		} else {
-			// если не был введен флаг -c вывод подходящих строк
-			for num, line := range linesToPrint {
-				if numbers {
-					fmt.Print(strconv.Itoa(num) + ":") // если был введен флаг -n, вывод номера строки перед самой строкой
-				}
-				fmt.Println(lines[line])
-			}
+			found = found || result.stats.matchedLines > 0
+		}
+		// при -q ответ известен после первой выбранной строки
+		return !opts.quiet || !found
+	})
+	if err != nil {
+		fatal(err)
+	}
+	// в формате JSON в конце выводится статистика по всем файлам
+	if opts.json {
+		if err = s.writeSummary(os.Stdout, time.Since(started)); err != nil {
+			fatal(err)
 		}
 	}
 
+	// как и в grep, при -q найденная строка важнее ошибок в других файлах
+	switch {
+	case opts.quiet && found:
+		os.Exit(exitMatch)
+	case failed:
+		os.Exit(exitError)
+	case found:
+		os.Exit(exitMatch)
+	default:
+		os.Exit(exitNoMatch)
+	}
 }
@@ -0,0 +1,466 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+type searchTest struct {
+	name     string
+	input    string
+	opts     searchOptions
+	expected string
+}
+
+var searchTests = []searchTest{
+	{"match", "error one\nok\nerror two\n", searchOptions{}, "error one\nerror two\n"},
+	{"no trailing newline", "ok\nerror", searchOptions{}, "error\n"},
+	{"invert", "error one\nok\nerror two\n", searchOptions{invert: true}, "ok\n"},
+	{"count", "error one\nok\nerror two\n", searchOptions{count: true}, "2\n"},
+	{"count with filename", "error one\nok\n", searchOptions{count: true, withFilename: true}, "test:1\n"},
+	{"line numbers", "ok\nerror\n", searchOptions{lineNumbers: true}, "2:error\n"},
+	{"filename", "ok\nerror\n", searchOptions{withFilename: true}, "test:error\n"},
+	{"after", "error\na\nb\nerror\nc\n", searchOptions{after: 1}, "error\na\n--\nerror\nc\n"},
+	{"before", "a\nb\nerror\nc\n", searchOptions{before: 1}, "b\nerror\n"},
+	{"before and after", "a\nb\nerror\nc\nd\ne\nf\nerror\n", searchOptions{before: 2, after: 1}, "a\nb\nerror\nc\n--\ne\nf\nerror\n"},
+	{"adjacent groups", "error\na\nb\nerror\n", searchOptions{before: 1, after: 1}, "error\na\nb\nerror\n"},
+	{"context marks", "a\nerror\nb\n", searchOptions{before: 1, after: 1, lineNumbers: true, withFilename: true}, "test-1-a\ntest:2:error\ntest-3-b\n"},
+	{"only matching", "error one error\nok\n", searchOptions{onlyMatching: true, lineNumbers: true}, "1:error\n1:error\n"},
+	{"byte offset", "ok\nerror\n", searchOptions{byteOffset: true}, "3:error\n"},
+	{"only matching byte offset", "ok\nan error\n", searchOptions{onlyMatching: true, byteOffset: true}, "6:error\n"},
+	{"only matching invert", "ok\nerror\n", searchOptions{onlyMatching: true, invert: true}, ""},
+	{"color", "an error\n", searchOptions{color: true, lineNumbers: true}, colorNumber + "1" + colorReset + colorSeparator + ":" + colorReset + "an " + colorMatch + "error" + colorReset + "\n"},
+	{"max count", "error 1\nerror 2\nerror 3\n", searchOptions{maxCount: 2}, "error 1\nerror 2\n"},
+	{"max count trailing context", "error 1\na\nerror 2\nerror 3\n", searchOptions{maxCount: 1, after: 2}, "error 1\na\nerror 2\n"},
+	{"max count with count", "error\nerror\nerror\n", searchOptions{maxCount: 2, count: true}, "2\n"},
+	{"files with matches", "ok\nerror\n", searchOptions{listFiles: true, listMatching: true, maxCount: 1}, "test\n"},
+	{"files with matches none", "ok\n", searchOptions{listFiles: true, listMatching: true, maxCount: 1}, ""},
+	{"files without match", "ok\n", searchOptions{listFiles: true, maxCount: 1}, "test\n"},
+	{"quiet", "error\n", searchOptions{quiet: true, maxCount: 1}, ""},
+	{"binary", "ok\x00\nerror\nerror\n", searchOptions{lineNumbers: true}, "Binary file test matches\n"},
+	{"binary without match", "ok\x00\nerror\n", searchOptions{binaryFiles: binaryWithoutMatch, count: true}, "0\n"},
+	{"binary as text", "ok\x00\nerror\n", searchOptions{binaryFiles: binaryText}, "error\n"},
+	{"binary count", "ok\x00\nerror\nerror\n", searchOptions{count: true}, "2\n"},
+	{"binary after header", strings.Repeat("ok\n", binaryPeekSize) + "error\x00\n", searchOptions{}, "Binary file test matches\n"},
+	{"binary after text match", "error\nok\n" + strings.Repeat("ok\n", binaryPeekSize) + "\x00\nerror\n", searchOptions{after: 1}, "error\nok\nBinary file test matches\n"},
+	{"replace", "an error here\n", searchOptions{replace: true, template: "<$0>"}, "an <error> here\n"},
+	{"replace invert", "ok\nerror\n", searchOptions{replace: true, template: "<$0>", invert: true}, "ok\n"},
+	{"invert context", "error\na\nerror\nerror\n", searchOptions{invert: true, before: 1, lineNumbers: true}, "1-error\n2:a\n"},
+}
+
+func TestSearch(t *testing.T) {
+	s := &searcher{matcher: &regexpMatcher{re: regexp.MustCompile("error")}}
+	for _, test := range searchTests {
+		s.opts = test.opts
+		output := &strings.Builder{}
+		if _, err := s.search("test", strings.NewReader(test.input), output); err != nil || output.String() != test.expected {
+			t.Errorf("%s: Output %q, %v was not equal to expected %q", test.name, output.String(), err, test.expected)
+		}
+	}
+}
+
+type replaceTest struct {
+	name     string
+	matcher  iMatcher
+	input    string
+	opts     searchOptions
+	expected string
+}
+
+var replaceTests = []replaceTest{
+	{"groups", &regexpMatcher{re: regexp.MustCompile(`user=(\w+) port=(\d+)`)}, "login user=bob port=22 ok\n",
+		searchOptions{}, "login bob at 22 ok\n"},
+	{"named groups", &regexpMatcher{re: regexp.MustCompile(`(?P<key>\w+)=(?P<value>\w+)`)}, "a=1 b=2\n",
+		searchOptions{template: "${value}:${key}"}, "1:a 2:b\n"},
+	{"only matching with line numbers", &regexpMatcher{re: regexp.MustCompile(`user=(\w+) port=(\d+)`)}, "skip\nuser=bob port=22 user=eve port=80\n",
+		searchOptions{onlyMatching: true, lineNumbers: true}, "2:bob at 22\n2:eve at 80\n"},
+	{"unmatched group", &regexpMatcher{re: regexp.MustCompile(`(\w+)=(\d+)?`)}, "a=\n",
+		searchOptions{template: "[$1|$2]"}, "[a|]\n"},
+	{"fixed strings", newFixedMatcher([]string{"error"}, false), "an error\n",
+		searchOptions{template: "$0 ($1)"}, "an error ()\n"},
+	{"word boundary", &boundaryMatcher{matcher: &regexpMatcher{re: regexp.MustCompile(`(\w+)d`)}}, "old bold\n",
+		searchOptions{template: "$1"}, "ol bol\n"},
+}
+
+func TestSearchReplace(t *testing.T) {
+	for _, test := range replaceTests {
+		s := &searcher{matcher: test.matcher, opts: test.opts}
+		s.opts.replace = true
+		if s.opts.template == "" {
+			s.opts.template = "$1 at $2"
+		}
+		output := &strings.Builder{}
+		if _, err := s.search("test", strings.NewReader(test.input), output); err != nil || output.String() != test.expected {
+			t.Errorf("%s: Output %q, %v was not equal to expected %q", test.name, output.String(), err, test.expected)
+		}
+	}
+}
+
+func TestWalkRecursive(t *testing.T) {
+	dir := t.TempDir()
+	for _, path := range []string{"a.log", "b.txt", "sub/c.log", "skip/d.log"} {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("error\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	walker := &inputWalker{recursive: true, includes: []string{"*.log"}, excludeDirs: []string{"skip"}}
+	visited := make([]string, 0)
+	err := walker.walk([]string{dir}, func(path string) error {
+		relPath, _ := filepath.Rel(dir, path)
+		visited = append(visited, filepath.ToSlash(relPath))
+		return nil
+	})
+	expected := []string{"a.log", "sub/c.log"}
+	if err != nil || !slices.Equal(visited, expected) {
+		t.Errorf("Output %v, %v was not equal to expected %v", visited, err, expected)
+	}
+}
+
+// writeFiles - функция для создания файлов с заданным содержимым во временной директории
+func writeFiles(t *testing.T, contents []string) []string {
+	dir := t.TempDir()
+	paths := make([]string, len(contents))
+	for i, content := range contents {
+		paths[i] = filepath.Join(dir, fmt.Sprintf("%03d.log", i))
+		if err := os.WriteFile(paths[i], []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return paths
+}
+
+// runPool - функция для поиска в файлах пулом из workers горутин
+func runPool(t *testing.T, opts searchOptions, paths []string, workers int) string {
+	s := &searcher{matcher: &regexpMatcher{re: regexp.MustCompile("error")}, opts: opts}
+	output := &strings.Builder{}
+	pool := &searchPool{searcher: s, workers: workers, output: output}
+	if err := pool.run(&inputWalker{}, paths, func(searchResult) bool { return true }); err != nil {
+		t.Fatal(err)
+	}
+	return output.String()
+}
+
+func TestPoolSeparatesFiles(t *testing.T) {
+	paths := writeFiles(t, []string{"error\nok\n", "nothing\n", "error\nok\n"})
+	output := runPool(t, searchOptions{after: 1}, paths, 2)
+	expected := "error\nok\n--\nerror\nok\n"
+	if output != expected {
+		t.Errorf("Output %q was not equal to expected %q", output, expected)
+	}
+}
+
+func TestPoolKeepsInputOrder(t *testing.T) {
+	contents := make([]string, 200)
+	for i := range contents {
+		contents[i] = strings.Repeat("error "+strconv.Itoa(i)+"\nok\n", i%7)
+	}
+	paths := writeFiles(t, contents)
+	for _, opts := range []searchOptions{{withFilename: true}, {count: true, withFilename: true}} {
+		serial := runPool(t, opts, paths, 1)
+		parallel := runPool(t, opts, paths, 8)
+		if serial != parallel {
+			t.Errorf("parallel output differs from serial output with %+v", opts)
+		}
+	}
+	counts := strings.Split(runPool(t, searchOptions{count: true}, paths, 8), "\n")
+	for i := range contents {
+		if counts[i] != strconv.Itoa(i%7) {
+			t.Errorf("Output %v was not equal to expected %v for file %v", counts[i], i%7, i)
+		}
+	}
+}
+
+type ahoCorasickTest struct {
+	patterns []string
+	text     string
+	expected [][]int
+}
+
+var ahoCorasickTests = []ahoCorasickTest{
+	{[]string{"he", "she", "his", "hers"}, "ushers", [][]int{{1, 4}}},
+	{[]string{"he", "hers"}, "hers he", [][]int{{0, 4}, {5, 7}}},
+	{[]string{"a", "ab", "bc"}, "abc", [][]int{{0, 2}}},
+	{[]string{"aa"}, "aaaa", [][]int{{0, 2}, {2, 4}}},
+	{[]string{"x"}, "abc", [][]int{}},
+	{[]string{"ошибка"}, "это ошибка", [][]int{{7, 19}}},
+}
+
+func TestAhoCorasick(t *testing.T) {
+	for _, test := range ahoCorasickTests {
+		automaton := newAhoCorasick(test.patterns)
+		matches := automaton.findAll(test.text)
+		if !slices.EqualFunc(matches, test.expected, slices.Equal[[]int]) {
+			t.Errorf("Output %v was not equal to expected %v for %q", matches, test.expected, test.text)
+		}
+		if contains := automaton.contains(test.text); contains != (len(test.expected) > 0) {
+			t.Errorf("Output %v was not equal to expected %v for %q", contains, len(test.expected) > 0, test.text)
+		}
+	}
+}
+
+func TestAhoCorasickManyPatterns(t *testing.T) {
+	patterns := make([]string, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		patterns = append(patterns, "ioc"+strconv.Itoa(i*7)+"x")
+	}
+	automaton := newAhoCorasick(patterns)
+	for i := 0; i < 7000; i += 13 {
+		text := "prefix ioc" + strconv.Itoa(i) + "x suffix"
+		expected := slices.ContainsFunc(patterns, func(pattern string) bool { return strings.Contains(text, pattern) })
+		if automaton.contains(text) != expected {
+			t.Errorf("Output %v was not equal to expected %v for %q", automaton.contains(text), expected, text)
+		}
+	}
+}
+
+// newLongestRegexp - функция для создания регулярного выражения с выбором самого длинного совпадения
+func newLongestRegexp(expression string) *regexpMatcher {
+	re := regexp.MustCompile(expression)
+	re.Longest()
+	return &regexpMatcher{re: re}
+}
+
+type matcherTest struct {
+	name     string
+	matcher  iMatcher
+	line     string
+	expected [][]int
+}
+
+var matcherTests = []matcherTest{
+	{"fixed substring", newFixedMatcher([]string{"ell"}, false), "hello", [][]int{{1, 4}}},
+	{"fixed is literal", newFixedMatcher([]string{"a.c"}, false), "abc a.c", [][]int{{4, 7}}},
+	{"fixed case sensitive", newFixedMatcher([]string{"Hello"}, false), "hello", [][]int{}},
+	{"fixed ignore case", newFixedMatcher([]string{"привет"}, true), "Скажи ПРИВЕТ", [][]int{{11, 23}}},
+	{"fixed ignore case kelvin", newFixedMatcher([]string{"k"}, true), "\u212a", [][]int{{0, 3}}},
+	{"regexp ignore case", newLongestRegexp("(?i)привет"), "ПРИВЕТ", [][]int{{0, 12}}},
+	{"word", &boundaryMatcher{matcher: newLongestRegexp("foo")}, "foo foobar _foo foo", [][]int{{0, 3}, {16, 19}}},
+	{"word unicode", &boundaryMatcher{matcher: newFixedMatcher([]string{"кот"}, false)}, "котик кот", [][]int{{11, 17}}},
+	{"word shorter match", &boundaryMatcher{matcher: newLongestRegexp("foo|foo bar")}, "foo barx", [][]int{{0, 3}}},
+	{"word shorter match multibyte", &boundaryMatcher{matcher: newLongestRegexp("a.")}, "aéb", [][]int{}},
+	{"word shorter match keeps runes", &boundaryMatcher{matcher: newLongestRegexp("é|é.")}, "éüb", [][]int{}},
+	{"word inside rejected match", &boundaryMatcher{matcher: newFixedMatcher([]string{"xfoo f", "foo"}, false)}, "xfoo foo", [][]int{{5, 8}}},
+	{"whole line", &boundaryMatcher{matcher: newFixedMatcher([]string{"abc"}, false), wholeLine: true}, "abc", [][]int{{0, 3}}},
+	{"whole line longer", &boundaryMatcher{matcher: newFixedMatcher([]string{"abc"}, false), wholeLine: true}, "abcd", [][]int{}},
+	{"whole line longest", &boundaryMatcher{matcher: newLongestRegexp("a|ab"), wholeLine: true}, "ab", [][]int{{0, 2}}},
+}
+
+func TestMatchers(t *testing.T) {
+	for _, test := range matcherTests {
+		matches := test.matcher.findMatches(test.line)
+		if !slices.EqualFunc(matches, test.expected, slices.Equal[[]int]) {
+			t.Errorf("%s: Output %v was not equal to expected %v", test.name, matches, test.expected)
+		}
+		if isMatch := test.matcher.matchLine(test.line); isMatch != (len(test.expected) > 0) {
+			t.Errorf("%s: Output %v was not equal to expected %v", test.name, isMatch, len(test.expected) > 0)
+		}
+	}
+}
+
+type commandLineTest struct {
+	args     []string
+	flags    []string
+	patterns []string
+	replace  []string
+	operands []string
+	err      bool
+}
+
+var commandLineTests = []commandLineTest{
+	{[]string{"-n", "-e", "-v", "file"}, []string{"-n"}, []string{"-v"}, nil, []string{"file"}, false},
+	{[]string{"-e", "a", "-e", "--", "-", "file"}, nil, []string{"a", "--"}, nil, []string{"-", "file"}, false},
+	{[]string{"--replace", "-x", "error", "--color=always"}, []string{"--color=always"}, nil, []string{"-x"}, []string{"error"}, false},
+	{[]string{"-epattern", "-e-v", "-f-", "file"}, nil, []string{"pattern", "-v"}, nil, []string{"file"}, false},
+	{[]string{"-A", "1", "-m", "-1", "error"}, nil, nil, nil, nil, true},
+	{[]string{"-Cx", "error"}, nil, nil, nil, nil, true},
+	{[]string{"error", "-e"}, nil, nil, nil, nil, true},
+}
+
+func TestParseCommandLine(t *testing.T) {
+	for _, test := range commandLineTests {
+		args, err := parseCommandLine(test.args)
+		if (err != nil) != test.err {
+			t.Errorf("Output %v was not equal to expected %v for %q", err, test.err, test.args)
+			continue
+		}
+		if test.err {
+			continue
+		}
+		if !slices.Equal(args.flags, test.flags) || !slices.Equal(args.flagValues("-e"), test.patterns) ||
+			!slices.Equal(args.flagValues("--replace"), test.replace) || !slices.Equal(args.operands, test.operands) {
+			t.Errorf("Output %q, %q, %q, %q was not equal to expected %q, %q, %q, %q for %q",
+				args.flags, args.flagValues("-e"), args.flagValues("--replace"), args.operands,
+				test.flags, test.patterns, test.replace, test.operands, test.args)
+		}
+	}
+}
+
+type contextTest struct {
+	args     []string
+	before   int
+	after    int
+	maxCount int
+}
+
+var contextTests = []contextTest{
+	{[]string{"-n", "-C1", "match", "file"}, 1, 1, 0},
+	{[]string{"-A", "1", "-B2", "-m1", "match"}, 2, 1, 1},
+	{[]string{"-C", "3", "-A0", "-m", "5", "-m2", "match"}, 3, 0, 2},
+	{[]string{"match"}, 0, 0, 0},
+}
+
+func TestGetContext(t *testing.T) {
+	for _, test := range contextTests {
+		args, err := parseCommandLine(test.args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		before, after := getContext(args)
+		maxCount, _ := args.flagNumber("-m")
+		if before != test.before || after != test.after || maxCount != test.maxCount {
+			t.Errorf("Output %v, %v, %v was not equal to expected %v, %v, %v for %q",
+				before, after, maxCount, test.before, test.after, test.maxCount, test.args)
+		}
+	}
+}
+
+func TestDashPattern(t *testing.T) {
+	args, err := parseCommandLine([]string{"-e", "-v", "file"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	patterns, paths := getPatterns(args)
+	matcher := newMatcher(patterns, args)
+	if args.hasFlag("-v") || !slices.Equal(paths, []string{"file"}) {
+		t.Errorf("Output %v, %q was not equal to expected %v, %q", args.hasFlag("-v"), paths, false, []string{"file"})
+	}
+	for line, expected := range map[string]bool{"a -v b": true, "plain": false} {
+		if isMatch := matcher.matchLine(line); isMatch != expected {
+			t.Errorf("Output %v was not equal to expected %v for %q", isMatch, expected, line)
+		}
+	}
+}
+
+func TestSearchJSON(t *testing.T) {
+	s := &searcher{matcher: &regexpMatcher{re: regexp.MustCompile(`(\w+) at (\d+)|(never)`)}, opts: searchOptions{json: true, after: 1}}
+	output := &strings.Builder{}
+	for _, input := range [][2]string{{"test", "ok\nbob at 10\nbye"}, {"empty", "nothing\n"}} {
+		stats, err := s.search(input[0], strings.NewReader(input[1]), output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.stats.add(stats)
+	}
+
+	events := make([]map[string]any, 0)
+	decoder := json.NewDecoder(strings.NewReader(output.String()))
+	for decoder.More() {
+		event := make(map[string]any)
+		if err := decoder.Decode(&event); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, event)
+	}
+	types := make([]string, len(events))
+	for i, event := range events {
+		types[i] = event["type"].(string)
+	}
+	expectedTypes := []string{"begin", "match", "context", "end"}
+	if !slices.Equal(types, expectedTypes) {
+		t.Fatalf("Output %v was not equal to expected %v", types, expectedTypes)
+	}
+
+	match, _ := json.Marshal(events[1]["data"])
+	expectedMatch := `{"absolute_offset":3,"line_number":2,"lines":{"text":"bob at 10\n"},"path":{"text":"test"},` +
+		`"submatches":[{"end":9,"groups":[{"end":3,"match":{"text":"bob"},"start":0},{"end":9,"match":{"text":"10"},"start":7},null],"match":{"text":"bob at 10"},"start":0}]}`
+	if string(match) != expectedMatch {
+		t.Errorf("Output %s was not equal to expected %s", match, expectedMatch)
+	}
+
+	// при --replace у каждого совпадения появляется результат подстановки
+	s.opts.replace, s.opts.template = true, "$1=$2"
+	output.Reset()
+	if _, err := s.search("test", strings.NewReader("bob at 10\n"), output); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output.String(), `"replacement":{"text":"bob=10"}`) {
+		t.Errorf("Output %s was not equal to expected replacement %s", output.String(), "bob=10")
+	}
+	context, _ := json.Marshal(events[2]["data"])
+	expectedContext := `{"absolute_offset":13,"line_number":3,"lines":{"text":"bye"},"path":{"text":"test"},"submatches":[]}`
+	if string(context) != expectedContext {
+		t.Errorf("Output %s was not equal to expected %s", context, expectedContext)
+	}
+	if s.stats.searches != 2 || s.stats.searchesWithMatch != 1 || s.stats.matchedLines != 1 {
+		t.Errorf("Output %+v does not count searches and matched lines", s.stats)
+	}
+}
+
+// failingReader - класс для проверки, что чтение прекращается до ошибки
+type failingReader struct{}
+
+func (f failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("read after the answer was known")
+}
+
+func TestSearchStopsEarly(t *testing.T) {
+	s := &searcher{matcher: &regexpMatcher{re: regexp.MustCompile("error")}, opts: searchOptions{listFiles: true, listMatching: true, maxCount: 1}}
+	input := io.MultiReader(strings.NewReader("ok\nerror\n"), failingReader{})
+	output := &strings.Builder{}
+	if stats, err := s.search("test", input, output); err != nil || stats.matchedLines != 1 || output.String() != "test\n" {
+		t.Errorf("Output %v, %q, %v was not equal to expected %v, %q", stats.matchedLines, output.String(), err, 1, "test\n")
+	}
+}
+
+// bzip2Input - строки "ok\nerror one\nerror two\n", сжатые bzip2
+var bzip2Input = []byte{
+	0x42, 0x5a, 0x68, 0x39, 0x31, 0x41, 0x59, 0x26, 0x53, 0x59, 0xae, 0x47, 0xf5, 0x67, 0x00, 0x00,
+	0x05, 0xd1, 0x80, 0x00, 0x10, 0x40, 0x00, 0x02, 0x09, 0x94, 0x80, 0x20, 0x00, 0x31, 0x06, 0x4c,
+	0x40, 0x94, 0x34, 0xca, 0x68, 0x93, 0x84, 0x62, 0x3b, 0x3c, 0xcd, 0x2c, 0x82, 0xef, 0x8b, 0xb9,
+	0x22, 0x9c, 0x28, 0x48, 0x57, 0x23, 0xfa, 0xb3, 0x80,
+}
+
+func TestDecompress(t *testing.T) {
+	const text = "ok\nerror one\nerror two\n"
+
+	gzipInput := &bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(gzipInput)
+	_, _ = gzipWriter.Write([]byte(text))
+	_ = gzipWriter.Close()
+
+	zstdInput := &bytes.Buffer{}
+	zstdWriter, _ := zstd.NewWriter(zstdInput)
+	_, _ = zstdWriter.Write([]byte(text))
+	_ = zstdWriter.Close()
+
+	paths := writeFiles(t, []string{gzipInput.String(), string(bzip2Input), zstdInput.String(), text, ""})
+	s := &searcher{matcher: &regexpMatcher{re: regexp.MustCompile("error")}, opts: searchOptions{count: true, decompress: true}}
+	for _, path := range paths[:4] {
+		output := &strings.Builder{}
+		if result := searchPath(s, path, output); result.failed || output.String() != "2\n" {
+			t.Errorf("Output %q, %v was not equal to expected %q for %s", output.String(), result.failed, "2\n", filepath.Base(path))
+		}
+	}
+	output := &strings.Builder{}
+	if result := searchPath(s, paths[4], output); result.failed || output.String() != "0\n" {
+		t.Errorf("Output %q, %v was not equal to expected %q for empty file", output.String(), result.failed, "0\n")
+	}
+}